@@ -0,0 +1,224 @@
+// Package anim records grid simulations step by step and encodes them as
+// animated GIFs.
+//
+// A simulation draws on a Recorder with Set, and calls Frame at the end of
+// each step. Only the cells that changed are kept for each frame, so even long
+// simulations, like the sand in day 14, stay small.
+package anim
+
+import (
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+
+	"github.com/clfs/aoc22/geom"
+)
+
+// A Color is an index into Palette.
+type Color uint8
+
+const (
+	Background Color = iota
+	White
+	Gray
+	Red
+	Orange
+	Yellow
+	Green
+	Blue
+)
+
+// Palette holds the colors used by every animation.
+var Palette = color.Palette{
+	Background: color.RGBA{0x0f, 0x0f, 0x23, 0xff},
+	White:      color.RGBA{0xff, 0xff, 0xff, 0xff},
+	Gray:       color.RGBA{0x80, 0x80, 0x80, 0xff},
+	Red:        color.RGBA{0xe6, 0x39, 0x46, 0xff},
+	Orange:     color.RGBA{0xf4, 0xa2, 0x61, 0xff},
+	Yellow:     color.RGBA{0xff, 0xff, 0x66, 0xff},
+	Green:      color.RGBA{0x00, 0x99, 0x00, 0xff},
+	Blue:       color.RGBA{0x45, 0x7b, 0x9d, 0xff},
+}
+
+// The delay on the last frame, in hundredths of a second, so that the end
+// result stays up for a moment before the animation loops.
+const holdLast = 200
+
+type cell struct {
+	p geom.Point
+	c Color
+}
+
+// A Recorder records the frames of an animation. The zero value is ready to
+// use.
+type Recorder struct {
+	CellSize int // The width and height of a cell in pixels. Zero means 4.
+	Every    int // Keep every Nth frame, merging the ones between. Zero means 1.
+	Delay    int // The delay between frames in hundredths of a second. Zero means 4.
+
+	cells     map[geom.Point]Color // The current picture.
+	pending   map[geom.Point]Color // Changes since the last kept frame.
+	frames    [][]cell             // The changes in each kept frame.
+	bounds    geom.Rect
+	hasBounds bool
+	steps     int
+}
+
+// Set colors the cell at p. The picture grows to include every cell that's
+// ever set, so setting cells to Background can be used to fix its size.
+func (r *Recorder) Set(p geom.Point, c Color) {
+	if r.cells == nil {
+		r.cells = make(map[geom.Point]Color)
+		r.pending = make(map[geom.Point]Color)
+	}
+
+	if r.hasBounds {
+		r.bounds = r.bounds.Include(p)
+	} else {
+		r.bounds = geom.Rect{Min: p, Max: p}
+		r.hasBounds = true
+	}
+
+	if r.cells[p] == c {
+		return
+	}
+	if c == Background {
+		delete(r.cells, p)
+	} else {
+		r.cells[p] = c
+	}
+	r.pending[p] = c
+}
+
+// At returns the color of the cell at p.
+func (r *Recorder) At(p geom.Point) Color {
+	return r.cells[p]
+}
+
+// Frame ends a step of the simulation. Unless it's skipped, the cells changed
+// since the last frame become a new frame.
+func (r *Recorder) Frame() {
+	r.steps++
+	if r.Every > 1 && r.steps%r.Every != 0 {
+		return
+	}
+	r.flush()
+}
+
+func (r *Recorder) flush() {
+	changes := make([]cell, 0, len(r.pending))
+	for p, c := range r.pending {
+		changes = append(changes, cell{p, c})
+	}
+	r.frames = append(r.frames, changes)
+	clear(r.pending)
+}
+
+// Len returns the number of frames kept so far.
+func (r *Recorder) Len() int {
+	return len(r.frames)
+}
+
+// Encode writes the animation to w as a looping GIF. Changes after the last
+// kept frame are added as a final frame.
+func (r *Recorder) Encode(w io.Writer) error {
+	if len(r.pending) > 0 {
+		r.flush()
+	}
+	if len(r.frames) == 0 {
+		return errors.New("anim: no frames")
+	}
+
+	size := r.CellSize
+	if size <= 0 {
+		size = 4
+	}
+	delay := r.Delay
+	if delay <= 0 {
+		delay = 4
+	}
+
+	g := &gif.GIF{
+		Config: image.Config{
+			ColorModel: Palette,
+			Width:      r.bounds.Width() * size,
+			Height:     r.bounds.Height() * size,
+		},
+	}
+
+	// Every frame after the first only covers the cells that changed, and is
+	// drawn over the frames before it.
+	state := make(map[geom.Point]Color)
+	for i, changes := range r.frames {
+		area := r.bounds
+		if i > 0 {
+			area = changedArea(changes, r.bounds.Min)
+		}
+		for _, c := range changes {
+			state[c.p] = c.c
+		}
+
+		g.Image = append(g.Image, r.render(state, area, size))
+		g.Delay = append(g.Delay, delay)
+		g.Disposal = append(g.Disposal, gif.DisposalNone)
+	}
+	g.Delay[len(g.Delay)-1] = holdLast
+
+	return gif.EncodeAll(w, g)
+}
+
+// changedArea returns the smallest Rect holding every changed cell, or just
+// the cell at def if nothing changed.
+func changedArea(changes []cell, def geom.Point) geom.Rect {
+	if len(changes) == 0 {
+		return geom.Rect{Min: def, Max: def}
+	}
+	area := geom.Rect{Min: changes[0].p, Max: changes[0].p}
+	for _, c := range changes[1:] {
+		area = area.Include(c.p)
+	}
+	return area
+}
+
+// render draws the cells of state in area, offset so that the top left of the
+// whole picture is at (0, 0).
+func (r *Recorder) render(state map[geom.Point]Color, area geom.Rect, size int) *image.Paletted {
+	origin := r.bounds.Min
+	lo := area.Min.Sub(origin).Mul(size)
+	hi := area.Max.Sub(origin).Add(geom.Pt(1, 1)).Mul(size)
+	img := image.NewPaletted(image.Rect(lo.X, lo.Y, hi.X, hi.Y), Palette)
+
+	for y := area.Min.Y; y <= area.Max.Y; y++ {
+		for x := area.Min.X; x <= area.Max.X; x++ {
+			c := state[geom.Pt(x, y)]
+			if c == Background {
+				continue // NewPaletted starts out as Background.
+			}
+			px := (x - origin.X) * size
+			py := (y - origin.Y) * size
+			for dy := 0; dy < size; dy++ {
+				for dx := 0; dx < size; dx++ {
+					img.SetColorIndex(px+dx, py+dy, uint8(c))
+				}
+			}
+		}
+	}
+	return img
+}
+
+type recorderKey struct{}
+
+// WithRecorder returns a copy of ctx that carries r. Solvers that support
+// animation draw on it.
+func WithRecorder(ctx context.Context, r *Recorder) context.Context {
+	return context.WithValue(ctx, recorderKey{}, r)
+}
+
+// RecorderFrom returns the Recorder carried by ctx, or nil if there isn't one.
+func RecorderFrom(ctx context.Context) *Recorder {
+	r, _ := ctx.Value(recorderKey{}).(*Recorder)
+	return r
+}
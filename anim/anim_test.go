@@ -0,0 +1,131 @@
+package anim
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/draw"
+	"image/gif"
+	"testing"
+
+	"github.com/clfs/aoc22/geom"
+)
+
+// decode decodes a GIF and draws each frame over the ones before it, like a
+// viewer would, returning the picture after each frame.
+func decode(t *testing.T, data []byte) (*gif.GIF, []*image.Paletted) {
+	t.Helper()
+
+	g, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	canvas := image.NewPaletted(image.Rect(0, 0, g.Config.Width, g.Config.Height), Palette)
+	var pictures []*image.Paletted
+	for _, img := range g.Image {
+		draw.Draw(canvas, img.Bounds(), img, img.Bounds().Min, draw.Src)
+		pictures = append(pictures, &image.Paletted{
+			Pix:     bytes.Clone(canvas.Pix),
+			Stride:  canvas.Stride,
+			Rect:    canvas.Rect,
+			Palette: canvas.Palette,
+		})
+	}
+	return g, pictures
+}
+
+func TestRecorder_Encode(t *testing.T) {
+	r := Recorder{CellSize: 2}
+
+	// A dot moving right along the top of a 3x2 picture, leaving a trail.
+	r.Set(geom.Pt(-1, 5), Background)
+	r.Set(geom.Pt(1, 6), Background)
+	r.Set(geom.Pt(-1, 5), Red)
+	r.Frame()
+	for x := 0; x <= 1; x++ {
+		r.Set(geom.Pt(x-1, 5), Blue)
+		r.Set(geom.Pt(x, 5), Red)
+		r.Frame()
+	}
+
+	if r.Len() != 3 {
+		t.Fatalf("Len() = %d, want 3", r.Len())
+	}
+
+	var buf bytes.Buffer
+	if err := r.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	g, pictures := decode(t, buf.Bytes())
+	if g.Config.Width != 6 || g.Config.Height != 4 {
+		t.Errorf("size = %dx%d, want 6x4", g.Config.Width, g.Config.Height)
+	}
+	if len(pictures) != 3 {
+		t.Fatalf("got %d frames, want 3", len(pictures))
+	}
+	if g.Delay[0] != 4 || g.Delay[2] != holdLast {
+		t.Errorf("delays = %v, want 4 at first and %d at the end", g.Delay, holdLast)
+	}
+
+	want := [][]Color{
+		{Red, Background, Background},
+		{Blue, Red, Background},
+		{Blue, Blue, Red},
+	}
+	for i, row := range want {
+		for x, c := range row {
+			// Check the bottom right pixel of each cell in the top row.
+			if got := Color(pictures[i].ColorIndexAt(2*x+1, 1)); got != c {
+				t.Errorf("frame %d: cell %d is color %d, want %d", i, x, got, c)
+			}
+		}
+		if got := Color(pictures[i].ColorIndexAt(0, 3)); got != Background {
+			t.Errorf("frame %d: bottom row is color %d, want background", i, got)
+		}
+	}
+}
+
+func TestRecorder_Every(t *testing.T) {
+	r := Recorder{Every: 3}
+	for x := 0; x < 7; x++ {
+		r.Set(geom.Pt(x, 0), Yellow)
+		r.Frame()
+	}
+	if r.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", r.Len())
+	}
+
+	var buf bytes.Buffer
+	if err := r.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	// The last step was skipped, but still makes it into a final frame.
+	_, pictures := decode(t, buf.Bytes())
+	if len(pictures) != 3 {
+		t.Fatalf("got %d frames, want 3", len(pictures))
+	}
+	if got := Color(pictures[2].ColorIndexAt(4*6, 0)); got != Yellow {
+		t.Errorf("last cell is color %d, want yellow", got)
+	}
+}
+
+func TestRecorder_Encode_Empty(t *testing.T) {
+	var r Recorder
+	if err := r.Encode(new(bytes.Buffer)); err == nil {
+		t.Error("Encode() of no frames succeeded")
+	}
+}
+
+func TestRecorderFrom(t *testing.T) {
+	if r := RecorderFrom(context.Background()); r != nil {
+		t.Errorf("RecorderFrom(Background) = %v, want nil", r)
+	}
+
+	r := new(Recorder)
+	if got := RecorderFrom(WithRecorder(context.Background(), r)); got != r {
+		t.Errorf("RecorderFrom(WithRecorder(r)) = %v, want r", got)
+	}
+}
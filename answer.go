@@ -0,0 +1,119 @@
+package aoc22
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+)
+
+// An Answer is the answer to one part of a puzzle. It holds either an integer
+// of any size or text, like the letters drawn by the day 10 CRT.
+//
+// Answers are comparable with ==. Integer answers compare equal whenever their
+// values are equal, no matter how they were constructed.
+type Answer struct {
+	num bool   // whether s is a base-10 integer
+	s   string // the integer in base 10, or the text
+}
+
+// Int returns an integer answer.
+func Int(n int) Answer {
+	return Answer{num: true, s: strconv.Itoa(n)}
+}
+
+// Int64 returns an integer answer.
+func Int64(n int64) Answer {
+	return Answer{num: true, s: strconv.FormatInt(n, 10)}
+}
+
+// BigInt returns an integer answer.
+func BigInt(n *big.Int) Answer {
+	return Answer{num: true, s: n.String()}
+}
+
+// Text returns a text answer.
+func Text(s string) Answer {
+	return Answer{s: s}
+}
+
+// IsZero reports whether a is the zero Answer, which holds nothing.
+func (a Answer) IsZero() bool {
+	return a == Answer{}
+}
+
+// IsInt reports whether a holds an integer.
+func (a Answer) IsInt() bool {
+	return a.num
+}
+
+// Int64 returns the integer held by a. It returns false if a doesn't hold an
+// integer, or if the integer overflows an int64.
+func (a Answer) Int64() (int64, bool) {
+	if !a.num {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(a.s, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// BigInt returns the integer held by a. It returns false if a doesn't hold an
+// integer.
+func (a Answer) BigInt() (*big.Int, bool) {
+	if !a.num {
+		return nil, false
+	}
+	return new(big.Int).SetString(a.s, 10)
+}
+
+// Equal reports whether a and b are the same answer.
+func (a Answer) Equal(b Answer) bool {
+	return a == b
+}
+
+// String returns the answer as it would be typed into the puzzle page.
+func (a Answer) String() string {
+	return a.s
+}
+
+// MarshalJSON encodes integers as JSON numbers and text as JSON strings. The
+// zero Answer is encoded as null.
+func (a Answer) MarshalJSON() ([]byte, error) {
+	switch {
+	case a.IsZero():
+		return []byte("null"), nil
+	case a.num:
+		return []byte(a.s), nil
+	default:
+		return json.Marshal(a.s)
+	}
+}
+
+// UnmarshalJSON decodes the encoding produced by MarshalJSON.
+func (a *Answer) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+
+	switch {
+	case string(data) == "null":
+		*a = Answer{}
+		return nil
+	case len(data) > 0 && data[0] == '"':
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*a = Text(s)
+		return nil
+	default:
+		n, ok := new(big.Int).SetString(string(data), 10)
+		if !ok {
+			return fmt.Errorf("invalid answer %s: not an integer or string", data)
+		}
+		*a = BigInt(n)
+		return nil
+	}
+}
@@ -0,0 +1,91 @@
+package aoc22
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+func TestAnswer_Equal(t *testing.T) {
+	big5, _ := new(big.Int).SetString("5", 10)
+
+	cases := []struct {
+		a, b Answer
+		want bool
+	}{
+		{Int(5), Int64(5), true},
+		{Int(5), BigInt(big5), true},
+		{Int(-5), Int(5), false},
+		{Int(5), Text("5"), false},
+		{Text("CMZ"), Text("CMZ"), true},
+		{Answer{}, Int(0), false},
+	}
+
+	for _, tc := range cases {
+		if got := tc.a.Equal(tc.b); got != tc.want {
+			t.Errorf("%#v.Equal(%#v) = %t, want %t", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestAnswer_JSON(t *testing.T) {
+	huge, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+
+	cases := []struct {
+		in   Answer
+		want string
+	}{
+		{Int(71924), `71924`},
+		{Int(-3), `-3`},
+		{BigInt(huge), `123456789012345678901234567890`},
+		{Text("SHQWSRBDL"), `"SHQWSRBDL"`},
+		{Text("#..#\n.##.\n"), `"#..#\n.##.\n"`},
+		{Answer{}, `null`},
+	}
+
+	for _, tc := range cases {
+		b, err := json.Marshal(tc.in)
+		if err != nil {
+			t.Errorf("Marshal(%v) error: %v", tc.in, err)
+			continue
+		}
+		if string(b) != tc.want {
+			t.Errorf("Marshal(%v) = %s, want %s", tc.in, b, tc.want)
+		}
+
+		var got Answer
+		if err := json.Unmarshal(b, &got); err != nil {
+			t.Errorf("Unmarshal(%s) error: %v", b, err)
+			continue
+		}
+		if got != tc.in {
+			t.Errorf("Unmarshal(%s) = %#v, want %#v", b, got, tc.in)
+		}
+	}
+
+	var a Answer
+	if err := json.Unmarshal([]byte(`1.5`), &a); err == nil {
+		t.Errorf("Unmarshal(1.5) = %#v, want error", a)
+	}
+}
+
+func TestAnswer_Int64(t *testing.T) {
+	huge, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+
+	cases := []struct {
+		in     Answer
+		want   int64
+		wantOk bool
+	}{
+		{Int(42), 42, true},
+		{BigInt(huge), 0, false},
+		{Text("42"), 0, false},
+	}
+
+	for _, tc := range cases {
+		got, ok := tc.in.Int64()
+		if got != tc.want || ok != tc.wantOk {
+			t.Errorf("%#v.Int64() = %d, %t; want %d, %t", tc.in, got, ok, tc.want, tc.wantOk)
+		}
+	}
+}
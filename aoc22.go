@@ -1,14 +1,15 @@
 package aoc22
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
-	"regexp"
-	"strconv"
+	"path/filepath"
 	"testing"
 )
 
-func ReadTestFile(t *testing.T, path string) []byte {
+func ReadTestFile(t testing.TB, path string) []byte {
 	t.Helper()
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -17,18 +18,71 @@ func ReadTestFile(t *testing.T, path string) []byte {
 	return data
 }
 
-var intsRegexp = regexp.MustCompile(`\d+`)
+// CheckAnswers checks part n of a day against the known answers in
+// answers.json, the manifest at the top of the repository, with one subtest
+// for each input. The day's package must be registered, which it is in its
+// own tests. Answers marked slow are skipped, as they are by aoc22 verify.
+// Having no answers at all is an error, so a typo in day or n can't pass
+// silently; a day without known answers should call t.Skip itself.
+//
+// Keeping the answers in the manifest means there's one place to update
+// them.
+func CheckAnswers(t *testing.T, day, n int) {
+	t.Helper()
+
+	path, err := findManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := ReadManifest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var found bool
+	for _, k := range m.Answers {
+		if k.Day != day || k.Part != n {
+			continue
+		}
+		found = true
+
+		t.Run(filepath.Base(k.Input), func(t *testing.T) {
+			if k.Slow {
+				t.Skip("slow; run aoc22 verify -slow")
+			}
+			got, ok, err := m.Verify(context.Background(), k)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ok {
+				t.Errorf("got %v, want %v", got, k.Answer)
+			}
+		})
+	}
+	if !found {
+		t.Fatalf("no answers for day %d part %d in %s", day, n, path)
+	}
+}
+
+// findManifest returns the path of answers.json in the current directory or
+// the nearest one above it.
+func findManifest() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	for {
+		path := filepath.Join(dir, "answers.json")
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
 
-// ReadInts returns a slice of all integers in the given string. For example,
-// "a 12\n3, b::4" becomes []int{12, 3, 4}.
-func ReadInts(s string) []int {
-	var result []int
-	for _, num := range intsRegexp.FindAllString(s, -1) {
-		n, err := strconv.Atoi(num)
-		if err != nil {
-			panic(fmt.Sprintf("bad number: %q", num))
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("answers.json not found")
 		}
-		result = append(result, n)
+		dir = parent
 	}
-	return result
 }
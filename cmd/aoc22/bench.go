@@ -0,0 +1,239 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"time"
+
+	"github.com/clfs/aoc22"
+)
+
+// A benchResult is the result of benchmarking one part on one input.
+type benchResult struct {
+	Day         int    `json:"day"`
+	Part        int    `json:"part"`
+	Input       string `json:"input"`
+	NsPerOp     int64  `json:"ns_per_op"`
+	BytesPerOp  int64  `json:"bytes_per_op"`
+	AllocsPerOp int64  `json:"allocs_per_op"`
+}
+
+func (r benchResult) key() string {
+	return fmt.Sprintf("%d/%d/%s", r.Day, r.Part, r.Input)
+}
+
+func benchCmd(args []string) error {
+	fs := flag.NewFlagSet("bench", flag.ContinueOnError)
+	var (
+		manifest  = fs.String("manifest", "answers.json", "known answers manifest, for the inputs")
+		day       = fs.Int("day", 0, "day to benchmark; 0 benchmarks every day")
+		baseline  = fs.String("baseline", "bench.json", "baseline file to compare against")
+		save      = fs.Bool("save", false, "save the results as the new baseline")
+		threshold = fs.Float64("threshold", 0.10, "slowdown past the baseline to report as a regression, as a fraction")
+		benchtime = fs.Duration("benchtime", time.Second, "minimum time to run each benchmark")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	m, err := aoc22.ReadManifest(*manifest)
+	if err != nil {
+		return err
+	}
+
+	base, err := readBaseline(*baseline)
+	if errors.Is(err, os.ErrNotExist) {
+		*save = true // The first run makes the baseline.
+	} else if err != nil {
+		return err
+	}
+
+	var (
+		results     []benchResult
+		regressions int
+	)
+	// Benchmarks are slow, so rows are printed as they finish, in fixed
+	// widths rather than through a tabwriter.
+	const row = "%-4v %-4v %-28v %14v %12v %10v  %v\n"
+	fmt.Printf(row, "DAY", "PART", "INPUT", "ns/op", "B/op", "allocs/op", "VS BASELINE")
+	for _, k := range benchInputs(m, *day) {
+		path := k.Input
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(m.Dir, path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		puzzle, ok := aoc22.Lookup(k.Day)
+		if !ok {
+			return fmt.Errorf("no solution for day %d", k.Day)
+		}
+
+		r := benchResult{Day: k.Day, Part: k.Part, Input: k.Input}
+		r.NsPerOp, r.BytesPerOp, r.AllocsPerOp, err = measure(*benchtime, func() error {
+			_, err := puzzle.Solve(context.Background(), k.Part, bytes.NewReader(data), k.Params)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("%v: %w", k, err)
+		}
+		results = append(results, r)
+
+		delta := ""
+		if old, ok := base[r.key()]; ok && old.NsPerOp > 0 {
+			change := float64(r.NsPerOp-old.NsPerOp) / float64(old.NsPerOp)
+			delta = fmt.Sprintf("%+.1f%%", 100*change)
+			if change > *threshold {
+				delta += " REGRESSION"
+				regressions++
+			}
+		}
+		fmt.Printf(row, r.Day, r.Part, r.Input, r.NsPerOp, r.BytesPerOp, r.AllocsPerOp, delta)
+	}
+
+	if *save {
+		if err := writeBaseline(*baseline, base, results); err != nil {
+			return err
+		}
+		fmt.Printf("\nsaved baseline to %s\n", *baseline)
+	}
+
+	if regressions > 0 {
+		return fmt.Errorf("%d benchmarks are more than %.0f%% slower than the baseline", regressions, 100**threshold)
+	}
+	return nil
+}
+
+// The most times measure runs a solution, as with go test -bench.
+const maxBenchRuns = 1e9
+
+// measure runs solve over and over for at least benchtime, and returns the
+// time and allocations each run took on average.
+//
+// Like go test -bench, it starts with one run, then keeps guessing from the
+// last count how many runs will take a little over benchtime, and only
+// reports the final count.
+func measure(benchtime time.Duration, solve func() error) (nsPerOp, bytesPerOp, allocsPerOp int64, err error) {
+	n := int64(1)
+	for {
+		runtime.GC()
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		start := time.Now()
+		for i := int64(0); i < n; i++ {
+			if err := solve(); err != nil {
+				return 0, 0, 0, err
+			}
+		}
+		elapsed := time.Since(start)
+		runtime.ReadMemStats(&after)
+
+		if elapsed >= benchtime || n >= maxBenchRuns {
+			return elapsed.Nanoseconds() / n,
+				int64(after.TotalAlloc-before.TotalAlloc) / n,
+				int64(after.Mallocs-before.Mallocs) / n,
+				nil
+		}
+
+		// Aim 20% past benchtime, but grow by no more than 100x at once in
+		// case the last count was too quick to time well.
+		next := 100 * n
+		if elapsed > 0 {
+			if guess := 1.2 * float64(n) * float64(benchtime) / float64(elapsed); guess < float64(next) {
+				next = int64(guess)
+			}
+		}
+		n = min(max(next, n+1), maxBenchRuns)
+	}
+}
+
+// benchInputs picks one input per day and part to benchmark: the full puzzle
+// input, unless that's too slow, in which case the last fast input listed.
+func benchInputs(m *aoc22.Manifest, day int) []aoc22.KnownAnswer {
+	type dayPart struct{ day, part int }
+
+	var (
+		order  []dayPart
+		chosen = make(map[dayPart]aoc22.KnownAnswer)
+	)
+	for _, k := range m.Answers {
+		if k.Slow || (day != 0 && k.Day != day) {
+			continue
+		}
+		dp := dayPart{k.Day, k.Part}
+		prev, ok := chosen[dp]
+		if !ok {
+			order = append(order, dp)
+		}
+		if !ok || filepath.Base(prev.Input) != "input.txt" {
+			chosen[dp] = k
+		}
+	}
+
+	result := make([]aoc22.KnownAnswer, len(order))
+	for i, dp := range order {
+		result[i] = chosen[dp]
+	}
+	return result
+}
+
+// readBaseline reads a baseline file into a map keyed by benchResult.key.
+func readBaseline(path string) (map[string]benchResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []benchResult
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	base := make(map[string]benchResult)
+	for _, r := range results {
+		base[r.key()] = r
+	}
+	return base, nil
+}
+
+// writeBaseline writes a baseline file. Results from the old baseline that
+// weren't rerun, such as other days', are kept.
+func writeBaseline(path string, old map[string]benchResult, results []benchResult) error {
+	rerun := make(map[string]bool)
+	for _, r := range results {
+		rerun[r.key()] = true
+	}
+
+	var merged []benchResult
+	for _, r := range old {
+		if !rerun[r.key()] {
+			merged = append(merged, r)
+		}
+	}
+	merged = append(merged, results...)
+	sort.Slice(merged, func(i, j int) bool {
+		a, b := merged[i], merged[j]
+		if a.Day != b.Day {
+			return a.Day < b.Day
+		}
+		if a.Part != b.Part {
+			return a.Part < b.Part
+		}
+		return a.Input < b.Input
+	})
+
+	data, err := json.MarshalIndent(merged, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
@@ -0,0 +1,26 @@
+package main
+
+// Import every day so that it registers its puzzle.
+import (
+	_ "github.com/clfs/aoc22/day1"
+	_ "github.com/clfs/aoc22/day10"
+	_ "github.com/clfs/aoc22/day11"
+	_ "github.com/clfs/aoc22/day12"
+	_ "github.com/clfs/aoc22/day13"
+	_ "github.com/clfs/aoc22/day14"
+	_ "github.com/clfs/aoc22/day15"
+	_ "github.com/clfs/aoc22/day16"
+	_ "github.com/clfs/aoc22/day17"
+	_ "github.com/clfs/aoc22/day18"
+	_ "github.com/clfs/aoc22/day19"
+	_ "github.com/clfs/aoc22/day2"
+	_ "github.com/clfs/aoc22/day20"
+	_ "github.com/clfs/aoc22/day21"
+	_ "github.com/clfs/aoc22/day3"
+	_ "github.com/clfs/aoc22/day4"
+	_ "github.com/clfs/aoc22/day5"
+	_ "github.com/clfs/aoc22/day6"
+	_ "github.com/clfs/aoc22/day7"
+	_ "github.com/clfs/aoc22/day8"
+	_ "github.com/clfs/aoc22/day9"
+)
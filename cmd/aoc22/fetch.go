@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/clfs/aoc22/fetch"
+)
+
+func fetchCmd(args []string) error {
+	fs := flag.NewFlagSet("fetch", flag.ContinueOnError)
+	var (
+		day    = fs.Int("day", 0, "day to fetch (required)")
+		year   = fs.Int("year", 2022, "event year")
+		output = fs.String("o", "-", `file to write the input to; "-" writes stdout`)
+		cache  = fs.String("cache", "", "cache directory (default is under the user cache directory)")
+		base   = fs.String("base", fetch.DefaultBaseURL, "website base URL")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	c, err := newFetchClient(*cache, *base)
+	if err != nil {
+		return err
+	}
+	data, err := c.Input(context.Background(), *year, *day)
+	if err != nil {
+		return err
+	}
+
+	if *output == "-" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(*output, data, 0o644); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "wrote %s (cached in %s)\n", *output, c.CachePath(*year, *day))
+	return nil
+}
+
+// newFetchClient returns a client for the website at base, caching inputs in
+// the given directory or the default one if it's empty.
+func newFetchClient(cache, base string) (*fetch.Client, error) {
+	if cache == "" {
+		dir, err := fetch.DefaultCacheDir()
+		if err != nil {
+			return nil, err
+		}
+		cache = dir
+	}
+
+	session, err := fetch.Session()
+	if err != nil {
+		return nil, err
+	}
+
+	return &fetch.Client{
+		BaseURL:  base,
+		Session:  session,
+		CacheDir: cache,
+	}, nil
+}
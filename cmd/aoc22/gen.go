@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/clfs/aoc22"
+)
+
+func genCmd(args []string) error {
+	fs := flag.NewFlagSet("gen", flag.ContinueOnError)
+	var (
+		day     = fs.Int("day", 0, "day to generate an input for (required)")
+		seed    = fs.Int64("seed", 1, "random seed; the same seed gives the same input")
+		size    = fs.Int("size", 0, "size of the input, which depends on the day; 0 is about as large as a real input")
+		output  = fs.String("o", "-", `file to write the input to; "-" writes it to stdout`)
+		check   = fs.Bool("check", false, "solve the input and compare with the known answers")
+		timeout = fs.Duration("timeout", 0, "time limit for each part with -check; 0 means no limit")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	puzzle, ok := aoc22.Lookup(*day)
+	if !ok {
+		return fmt.Errorf("no solution for day %d", *day)
+	}
+	if puzzle.Generate == nil {
+		return fmt.Errorf("day %d has no generator", *day)
+	}
+
+	var buf bytes.Buffer
+	g, err := puzzle.Generate(&buf, rand.New(rand.NewSource(*seed)), *size)
+	if err != nil {
+		return fmt.Errorf("day %d: %w", *day, err)
+	}
+	if err := writeOutput(*output, buf.Bytes()); err != nil {
+		return err
+	}
+
+	// The answers go to stderr, so that stdout holds just the input.
+	if len(g.Params) > 0 {
+		fmt.Fprintf(os.Stderr, "params: %s\n", paramArgs(g.Params))
+	}
+	for n := 1; n <= 2; n++ {
+		if want, ok := g.Answer(n); ok {
+			printAnswer(os.Stderr, *day, n, want)
+		} else {
+			fmt.Fprintf(os.Stderr, "day %d part %d: unknown\n", *day, n)
+		}
+	}
+
+	if !*check {
+		return nil
+	}
+
+	var failed []int
+	for n := 1; n <= 2; n++ {
+		start := time.Now()
+		got, err := solveWithin(context.Background(), *timeout, puzzle, n, buf.Bytes(), g.Params)
+		elapsed := time.Since(start).Round(time.Microsecond)
+		if err != nil {
+			return fmt.Errorf("day %d part %d: %w", *day, n, err)
+		}
+
+		result := "no known answer"
+		if want, ok := g.Answer(n); ok {
+			result = "ok"
+			if !got.Equal(want) {
+				result = fmt.Sprintf("FAIL, want %s", quoteAnswer(want))
+				failed = append(failed, n)
+			}
+		}
+		fmt.Fprintf(os.Stderr, "check part %d: got %s in %v: %s\n", n, quoteAnswer(got), elapsed, result)
+	}
+	if len(failed) > 0 {
+		return errors.New("wrong answers")
+	}
+	return nil
+}
+
+// writeOutput writes data to the named file, or to stdout if the name is "-".
+func writeOutput(name string, data []byte) error {
+	if name == "-" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(name, data, 0o644)
+}
+
+// paramArgs formats params as the flags that set them, like "-bound 20 -y 10".
+func paramArgs(params aoc22.Params) string {
+	var names []string
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		fmt.Fprintf(&b, "-%s %d", name, params[name])
+	}
+	return b.String()
+}
@@ -0,0 +1,80 @@
+// Command aoc22 runs the Advent of Code 2022 solutions.
+//
+// Usage:
+//
+//	aoc22 <command> [flags]
+//
+// The commands are:
+//
+//	bench  benchmark every solution and compare with a saved baseline
+//	fetch  download a day's puzzle input, or read it from the cache
+//	gen    generate a random input for a day, with its answers where known
+//	new    create the skeleton of a new day's package
+//	play   step through a day's simulation in the terminal
+//	run    solve one or both parts of a day
+//	serve  serve the solvers over HTTP, with a JSON API and an upload form
+//	submit post an answer, unless it's already known to be wrong
+//	verify check every solution against the known answers in answers.json
+//
+// Run "aoc22 <command> -h" for a command's flags.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// A command is a subcommand of aoc22. It receives the arguments after the
+// command name.
+type command func(args []string) error
+
+var commands = map[string]command{
+	"bench":  benchCmd,
+	"fetch":  fetchCmd,
+	"gen":    genCmd,
+	"new":    newCmd,
+	"play":   playCmd,
+	"run":    runCmd,
+	"serve":  serveCmd,
+	"submit": submitCmd,
+	"verify": verifyCmd,
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: aoc22 <command> [flags]")
+	fmt.Fprintln(os.Stderr, "commands:")
+
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "\t%s\n", name)
+	}
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "aoc22: unknown command %q\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+
+	if err := cmd(os.Args[2:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(2)
+		}
+		fmt.Fprintf(os.Stderr, "aoc22 %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
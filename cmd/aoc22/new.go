@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/clfs/aoc22/fetch"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+var templates = template.Must(template.ParseFS(templateFS, "templates/*.tmpl"))
+
+func newCmd(args []string) error {
+	fs := flag.NewFlagSet("new", flag.ContinueOnError)
+	var (
+		day      = fs.Int("day", 0, "day to create (required)")
+		dir      = fs.String("dir", ".", "root of the repository")
+		download = fs.Bool("fetch", false, "also download the day's input to testdata/input.txt")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *day < 1 || *day > 25 {
+		return fmt.Errorf("invalid day %d", *day)
+	}
+	module, err := modulePath(filepath.Join(*dir, "go.mod"))
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("day%d", *day)
+	pkgDir := filepath.Join(*dir, name)
+	if _, err := os.Stat(pkgDir); err == nil {
+		return fmt.Errorf("%s already exists", pkgDir)
+	}
+
+	var input []byte
+	if *download {
+		c, err := newFetchClient("", fetch.DefaultBaseURL)
+		if err != nil {
+			return err
+		}
+		if input, err = c.Input(context.Background(), 2022, *day); err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Join(pkgDir, "testdata"), 0o755); err != nil {
+		return err
+	}
+	files := []struct {
+		tmpl, path string
+	}{
+		{"day.go.tmpl", filepath.Join(pkgDir, name+".go")},
+		{"day_test.go.tmpl", filepath.Join(pkgDir, name+"_test.go")},
+	}
+	for _, f := range files {
+		if err := writeTemplate(f.path, f.tmpl, struct{ Day int }{*day}); err != nil {
+			return err
+		}
+	}
+	// The example goes in small.txt by hand.
+	if err := os.WriteFile(filepath.Join(pkgDir, "testdata", "small.txt"), nil, 0o644); err != nil {
+		return err
+	}
+	if input != nil {
+		if err := os.WriteFile(filepath.Join(pkgDir, "testdata", "input.txt"), input, 0o644); err != nil {
+			return err
+		}
+	}
+
+	if err := addDayImport(filepath.Join(*dir, "cmd", "aoc22", "days.go"), module+"/"+name); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "created %s; paste the example into %s\n", pkgDir, filepath.Join(pkgDir, "testdata", "small.txt"))
+	return nil
+}
+
+// writeTemplate executes the named template with data, and writes the
+// formatted result to path.
+func writeTemplate(path, name string, data any) error {
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, name, data); err != nil {
+		return err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return os.WriteFile(path, src, 0o644)
+}
+
+// modulePath returns the module path declared in a go.mod file.
+func modulePath(gomod string) (string, error) {
+	data, err := os.ReadFile(gomod)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("%s not found; run from the root of the repository or set -dir", gomod)
+	}
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if path, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+			return strings.Trim(strings.TrimSpace(path), `"`), nil
+		}
+	}
+	return "", fmt.Errorf("%s has no module line", gomod)
+}
+
+// addDayImport adds an import of pkg to the list of days in the file at path.
+func addDayImport(path, pkg string) error {
+	f, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.ImportsOnly)
+	if err != nil {
+		return err
+	}
+
+	imports := []string{pkg}
+	for _, spec := range f.Imports {
+		p, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			return err
+		}
+		if p == pkg {
+			return nil
+		}
+		imports = append(imports, p)
+	}
+	sort.Strings(imports)
+
+	return writeTemplate(path, "days.go.tmpl", imports)
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/clfs/aoc22"
+)
+
+// The fastest the screen is redrawn while playing. Faster speeds take several
+// steps per redraw.
+const maxFPS = 30
+
+const playHelp = "enter: step, b: back, p: play/pause, + and -: speed, g N: go to step N, q: quit"
+
+func playCmd(args []string) error {
+	fs := flag.NewFlagSet("play", flag.ContinueOnError)
+	var (
+		day     = fs.Int("day", 0, "day to replay (required)")
+		part    = fs.Int("part", 1, "part to replay, 1 or 2")
+		input   = fs.String("input", "", "puzzle input file (required; stdin is for commands)")
+		speed   = fs.Float64("speed", 10, "steps per second while playing")
+		history = fs.Int("history", aoc22.DefaultSnapshots, "number of steps kept for stepping back")
+	)
+	params := paramFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *input == "" || *input == "-" {
+		return errors.New("-input must name a file")
+	}
+	if *speed <= 0 {
+		return fmt.Errorf("invalid speed %v", *speed)
+	}
+
+	puzzle, ok := aoc22.Lookup(*day)
+	if !ok {
+		return fmt.Errorf("no solution for day %d", *day)
+	}
+	data, err := os.ReadFile(*input)
+	if err != nil {
+		return err
+	}
+	sim, err := puzzle.StartSimulation(*part, bytes.NewReader(data), params.set(fs))
+	if err != nil {
+		return err
+	}
+
+	p := &player{
+		Player: aoc22.NewPlayer(sim, *history),
+		speed:  *speed,
+		w:      bufio.NewWriter(os.Stdout),
+	}
+	return p.run(readLines(os.Stdin))
+}
+
+// readLines sends each line read from r to the returned channel, and closes
+// it at the end of r.
+func readLines(r io.Reader) <-chan string {
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		s := bufio.NewScanner(r)
+		for s.Scan() {
+			lines <- s.Text()
+		}
+	}()
+	return lines
+}
+
+// A player runs an aoc22.Player in the terminal.
+type player struct {
+	*aoc22.Player
+	speed   float64 // Steps per second while playing.
+	playing bool
+	status  string // A message for the last command.
+	w       *bufio.Writer
+}
+
+func (p *player) run(commands <-chan string) error {
+	var (
+		ticker *time.Ticker
+		tick   <-chan time.Time // Nil while paused.
+	)
+	defer func() {
+		if ticker != nil {
+			ticker.Stop()
+		}
+	}()
+
+	for {
+		if err := p.draw(); err != nil {
+			return err
+		}
+
+		select {
+		case cmd, ok := <-commands:
+			if !ok {
+				return nil
+			}
+			if quit := p.do(cmd); quit {
+				return nil
+			}
+		case <-tick:
+			for i := 0; i < p.stepsPerTick(); i++ {
+				if !p.Forward() {
+					p.playing = false
+					p.status = "end of simulation"
+					break
+				}
+			}
+		}
+
+		// Restart the ticker, since the speed may have changed.
+		if ticker != nil {
+			ticker.Stop()
+			ticker, tick = nil, nil
+		}
+		if p.playing {
+			ticker = time.NewTicker(p.interval())
+			tick = ticker.C
+		}
+	}
+}
+
+// do runs a command typed by the user. It reports whether to quit.
+func (p *player) do(cmd string) (quit bool) {
+	p.status = ""
+
+	fields := strings.Fields(cmd)
+	if len(fields) == 0 {
+		fields = []string{"n"}
+	}
+
+	switch fields[0] {
+	case "n":
+		p.playing = false
+		if !p.Forward() {
+			p.status = "end of simulation"
+		}
+	case "b":
+		p.playing = false
+		if !p.Back() {
+			p.status = "no earlier steps kept"
+		}
+	case "p":
+		p.playing = !p.playing && !p.Done()
+	case "+":
+		p.speed *= 2
+	case "-":
+		p.speed /= 2
+	case "g":
+		if len(fields) != 2 {
+			p.status = "usage: g N"
+			break
+		}
+		n, err := strconv.Atoi(fields[1])
+		if err != nil || n < 0 {
+			p.status = fmt.Sprintf("invalid step %q", fields[1])
+			break
+		}
+		p.Seek(n)
+		if p.Step() != n {
+			p.status = fmt.Sprintf("step %d is out of range", n)
+		}
+	case "q":
+		return true
+	default:
+		p.status = fmt.Sprintf("unknown command %q; %s", cmd, playHelp)
+	}
+	return false
+}
+
+// interval returns the time between redraws while playing.
+func (p *player) interval() time.Duration {
+	d := time.Duration(float64(time.Second) / p.speed)
+	return max(d, time.Second/maxFPS)
+}
+
+// stepsPerTick returns the number of steps to take per redraw while playing.
+func (p *player) stepsPerTick() int {
+	return max(1, int(p.speed/maxFPS))
+}
+
+// draw clears the terminal and draws the current step.
+func (p *player) draw() error {
+	state := "paused"
+	if p.playing {
+		state = "playing"
+	}
+
+	fmt.Fprint(p.w, "\x1b[H\x1b[2J") // Move to the top left and clear.
+	fmt.Fprintln(p.w, strings.TrimRight(p.Frame(), "\n"))
+	fmt.Fprintf(p.w, "\n\x1b[7m step %d of %d, %s at %g steps/s \x1b[0m\n", p.Step(), p.Last(), state, p.speed)
+	if p.status != "" {
+		fmt.Fprintln(p.w, p.status)
+	} else {
+		fmt.Fprintln(p.w, playHelp)
+	}
+	fmt.Fprint(p.w, "> ")
+	return p.w.Flush()
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/clfs/aoc22"
+	"github.com/clfs/aoc22/anim"
+)
+
+func runCmd(args []string) error {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	var (
+		day     = fs.Int("day", 0, "day to solve (required)")
+		part    = fs.Int("part", 0, "part to solve; 0 solves both")
+		input   = fs.String("input", "-", `puzzle input file; "-" reads stdin`)
+		timeout = fs.Duration("timeout", 0, "time limit for each part; 0 means no limit")
+		trace   = fs.String("trace", "", `file to write simulation events to as JSON lines; "-" writes them to stderr as text`)
+		gifFile = fs.String("gif", "", "file to write an animation of the simulation to, for days 9, 10 and 14")
+		rec     anim.Recorder
+	)
+	fs.IntVar(&rec.Every, "gif-every", 1, "keep every Nth frame of the animation")
+	fs.IntVar(&rec.CellSize, "gif-cell", 4, "width and height of each animated cell in pixels")
+	fs.IntVar(&rec.Delay, "gif-delay", 4, "delay between animation frames in hundredths of a second")
+	params := paramFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	puzzle, ok := aoc22.Lookup(*day)
+	if !ok {
+		return fmt.Errorf("no solution for day %d", *day)
+	}
+
+	parts := []int{1, 2}
+	switch *part {
+	case 0:
+	case 1, 2:
+		parts = []int{*part}
+	default:
+		return fmt.Errorf("invalid part %d", *part)
+	}
+	if *gifFile != "" && len(parts) != 1 {
+		return errors.New("-gif needs -part")
+	}
+
+	data, err := readInput(*input)
+	if err != nil {
+		return err
+	}
+
+	// An interrupt stops the current part, like a timeout does.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	switch *trace {
+	case "":
+	case "-":
+		h := slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})
+		ctx = aoc22.WithTracer(ctx, aoc22.SlogTracer{Logger: slog.New(h), Level: slog.LevelDebug})
+	default:
+		f, err := os.Create(*trace)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w := bufio.NewWriter(f)
+		defer w.Flush()
+		ctx = aoc22.WithTracer(ctx, aoc22.NewJSONTracer(w))
+	}
+	if *gifFile != "" {
+		ctx = anim.WithRecorder(ctx, &rec)
+	}
+
+	for _, n := range parts {
+		answer, err := solveWithin(ctx, *timeout, puzzle, n, data, params.set(fs))
+		if err != nil {
+			if !answer.IsZero() && (errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled)) {
+				fmt.Fprintf(os.Stderr, "day %d part %d: stopped early; best answer so far:\n", *day, n)
+				printAnswer(os.Stderr, *day, n, answer)
+			}
+			return fmt.Errorf("day %d part %d: %w", *day, n, err)
+		}
+		printAnswer(os.Stdout, *day, n, answer)
+	}
+
+	if *gifFile != "" {
+		if rec.Len() == 0 {
+			return fmt.Errorf("day %d doesn't record animations", *day)
+		}
+		return writeGIF(*gifFile, &rec)
+	}
+	return nil
+}
+
+// writeGIF encodes the animation recorded by rec to the named file.
+func writeGIF(name string, rec *anim.Recorder) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	if err := rec.Encode(w); err != nil {
+		f.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// solveWithin solves part n on data, stopping after timeout if it's positive.
+func solveWithin(ctx context.Context, timeout time.Duration, puzzle aoc22.Puzzle, n int, data []byte, params aoc22.Params) (aoc22.Answer, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return puzzle.Solve(ctx, n, bytes.NewReader(data), params)
+}
+
+// readInput reads the puzzle input from the named file, or from stdin if the
+// name is "-".
+func readInput(name string) ([]byte, error) {
+	if name == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(name)
+}
+
+// printAnswer prints an answer, moving multi-line answers onto their own lines.
+func printAnswer(w io.Writer, day, part int, answer aoc22.Answer) {
+	s := answer.String()
+	if strings.Contains(s, "\n") {
+		fmt.Fprintf(w, "day %d part %d:\n%s", day, part, s)
+		if !strings.HasSuffix(s, "\n") {
+			fmt.Fprintln(w)
+		}
+		return
+	}
+	fmt.Fprintf(w, "day %d part %d: %s\n", day, part, s)
+}
+
+// paramValues holds the flags defined for registered puzzle parameters.
+type paramValues map[string]*int
+
+// paramFlags defines an int flag for every parameter of every registered
+// puzzle. Days that share a parameter name share its flag.
+func paramFlags(fs *flag.FlagSet) paramValues {
+	usages := make(map[string][]string)
+	for _, p := range aoc22.Puzzles() {
+		for _, param := range p.Params {
+			usages[param.Name] = append(usages[param.Name],
+				fmt.Sprintf("day %d: %s (default %d)", p.Day, param.Usage, param.Default))
+		}
+	}
+
+	var names []string
+	for name := range usages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	values := make(paramValues)
+	for _, name := range names {
+		values[name] = fs.Int(name, 0, strings.Join(usages[name], "; "))
+	}
+	return values
+}
+
+// set returns the parameters that were set on the command line. Parameters
+// left unset fall back to each puzzle's defaults.
+func (pv paramValues) set(fs *flag.FlagSet) aoc22.Params {
+	params := make(aoc22.Params)
+	fs.Visit(func(f *flag.Flag) {
+		if v, ok := pv[f.Name]; ok {
+			params[f.Name] = *v
+		}
+	})
+	return params
+}
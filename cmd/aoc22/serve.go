@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/clfs/aoc22/server"
+)
+
+func serveCmd(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	var (
+		addr     = fs.String("addr", ":8080", "address to listen on")
+		maxInput = fs.Int64("max-input", server.DefaultMaxInputSize, "largest input accepted, in bytes")
+		timeout  = fs.Duration("timeout", server.DefaultTimeout, "time limit for each solve")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ln, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{
+		Handler:           &server.Server{MaxInputSize: *maxInput, Timeout: *timeout},
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// On an interrupt, stop taking requests and let the running ones finish.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), *timeout)
+		defer cancel()
+		srv.Shutdown(shutdown)
+	}()
+
+	fmt.Fprintf(os.Stderr, "serving on http://%s\n", ln.Addr())
+	if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
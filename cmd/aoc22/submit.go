@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/clfs/aoc22"
+	"github.com/clfs/aoc22/fetch"
+	"github.com/clfs/aoc22/submit"
+)
+
+func submitCmd(args []string) error {
+	fs := flag.NewFlagSet("submit", flag.ContinueOnError)
+	var (
+		day     = fs.Int("day", 0, "day to submit (required)")
+		part    = fs.Int("part", 0, "part to submit, 1 or 2 (required)")
+		year    = fs.Int("year", 2022, "event year")
+		answer  = fs.String("answer", "", "answer to submit; if empty, the answer is computed")
+		input   = fs.String("input", "", `puzzle input file; "-" reads stdin, and empty fetches the input`)
+		history = fs.String("history", "", "history file (default is under the user configuration directory)")
+		cache   = fs.String("cache", "", "input cache directory (default is under the user cache directory)")
+		base    = fs.String("base", fetch.DefaultBaseURL, "website base URL")
+	)
+	params := paramFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *part != 1 && *part != 2 {
+		return fmt.Errorf("invalid part %d", *part)
+	}
+
+	fc, err := newFetchClient(*cache, *base)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	if *answer == "" {
+		puzzle, ok := aoc22.Lookup(*day)
+		if !ok {
+			return fmt.Errorf("no solution for day %d; use -answer", *day)
+		}
+
+		var data []byte
+		if *input == "" {
+			data, err = fc.Input(ctx, *year, *day)
+		} else {
+			data, err = readInput(*input)
+		}
+		if err != nil {
+			return err
+		}
+
+		a, err := puzzle.Solve(ctx, *part, bytes.NewReader(data), params.set(fs))
+		if err != nil {
+			return fmt.Errorf("day %d part %d: %w", *day, *part, err)
+		}
+		if strings.Contains(a.String(), "\n") {
+			printAnswer(os.Stderr, *day, *part, a)
+			return errors.New("the answer must be read off the drawing above; use -answer")
+		}
+		*answer = a.String()
+	}
+
+	if *history == "" {
+		*history, err = submit.DefaultHistoryFile()
+		if err != nil {
+			return err
+		}
+	}
+	h, err := submit.LoadHistory(*history)
+	if err != nil {
+		return err
+	}
+
+	if err := h.Check(*year, *day, *part, *answer); err != nil {
+		return err
+	}
+	if b := h.Bracket(*year, *day, *part); !b.Contains(*answer) {
+		fmt.Fprintf(os.Stderr, "warning: past guesses say the answer is %v, but submitting %s anyway\n", b, *answer)
+	}
+
+	sc := &submit.Client{BaseURL: *base, Session: fc.Session}
+	res, err := sc.Submit(ctx, *year, *day, *part, *answer)
+	if err != nil {
+		return err
+	}
+
+	h.Record(submit.Attempt{
+		Year:    *year,
+		Day:     *day,
+		Part:    *part,
+		Answer:  *answer,
+		Outcome: res.Outcome,
+		Time:    time.Now(),
+	})
+	if err := h.Save(*history); err != nil {
+		return err
+	}
+
+	fmt.Printf("day %d part %d: %s is %v\n", *day, *part, *answer, res.Outcome)
+	if res.Wait > 0 {
+		fmt.Printf("wait %v before submitting again\n", res.Wait)
+	}
+	if res.Outcome == submit.Unknown {
+		fmt.Println(res.Message)
+	}
+	return nil
+}
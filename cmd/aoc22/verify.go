@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/clfs/aoc22"
+)
+
+func verifyCmd(args []string) error {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	var (
+		manifest = fs.String("manifest", "answers.json", "known answers manifest")
+		day      = fs.Int("day", 0, "day to verify; 0 verifies every day")
+		slow     = fs.Bool("slow", false, "also verify answers marked slow")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	m, err := aoc22.ReadManifest(*manifest)
+	if err != nil {
+		return err
+	}
+
+	var (
+		failures        []string
+		passed, skipped int
+		known           = make(map[int]bool)
+	)
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "DAY\tPART\tINPUT\tRESULT\tTIME")
+	for _, k := range m.Answers {
+		if *day != 0 && k.Day != *day {
+			continue
+		}
+		known[k.Day] = true
+
+		if k.Slow && !*slow {
+			skipped++
+			fmt.Fprintf(tw, "%d\t%d\t%s\tskip (slow)\t\n", k.Day, k.Part, k.Input)
+			continue
+		}
+
+		start := time.Now()
+		got, ok, err := m.Verify(context.Background(), k)
+		elapsed := time.Since(start).Round(time.Microsecond)
+
+		result := "ok"
+		switch {
+		case err != nil:
+			result = "ERROR"
+			failures = append(failures, fmt.Sprintf("%v: %v", k, err))
+		case !ok:
+			result = "FAIL"
+			failures = append(failures, fmt.Sprintf("%v: got %s, want %s", k, quoteAnswer(got), quoteAnswer(k.Answer)))
+		default:
+			passed++
+		}
+		fmt.Fprintf(tw, "%d\t%d\t%s\t%s\t%v\n", k.Day, k.Part, k.Input, result, elapsed)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	for _, p := range aoc22.Puzzles() {
+		if (*day == 0 || p.Day == *day) && !known[p.Day] {
+			fmt.Printf("day %d has no known answers\n", p.Day)
+		}
+	}
+
+	fmt.Printf("\n%d passed, %d failed, %d skipped\n", passed, len(failures), skipped)
+	if len(failures) > 0 {
+		fmt.Println()
+		for _, f := range failures {
+			fmt.Println(f)
+		}
+		return fmt.Errorf("%d of %d answers failed", len(failures), len(failures)+passed)
+	}
+	return nil
+}
+
+// quoteAnswer formats an answer on one line, quoting text answers so that
+// drawings stay readable.
+func quoteAnswer(a aoc22.Answer) string {
+	if a.IsInt() {
+		return a.String()
+	}
+	return fmt.Sprintf("%q", strings.TrimSuffix(a.String(), "\n"))
+}
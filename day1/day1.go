@@ -2,18 +2,21 @@ package day1
 
 import (
 	"bufio"
+	"context"
+	"fmt"
 	"io"
+	"slices"
 	"strconv"
 
-	"golang.org/x/exp/slices"
+	"github.com/clfs/aoc22"
 )
 
-func parse(r io.Reader) [][]int {
+func parse(r io.Reader) ([][]int, error) {
 	var result [][]int
 	var group []int
 
 	scanner := bufio.NewScanner(r)
-	for scanner.Scan() {
+	for lineNum := 1; scanner.Scan(); lineNum++ {
 		line := scanner.Text()
 
 		if line == "" {
@@ -27,18 +30,28 @@ func parse(r io.Reader) [][]int {
 
 		n, err := strconv.Atoi(line)
 		if err != nil {
-			panic(err)
+			return nil, aoc22.NewParseError(1, lineNum, line, err)
 		}
 		group = append(group, n)
 	}
 
-	return result
+	// The last group may not be followed by a blank line.
+	if len(group) > 0 {
+		result = append(result, group)
+	}
+
+	return result, scanner.Err()
 }
 
-func Part1(r io.Reader) int {
+func Part1(ctx context.Context, r io.Reader) (aoc22.Answer, error) {
+	groups, err := parse(r)
+	if err != nil {
+		return aoc22.Answer{}, err
+	}
+
 	var sum, best int
 
-	for _, group := range parse(r) {
+	for _, group := range groups {
 		for _, n := range group {
 			sum += n
 		}
@@ -48,13 +61,18 @@ func Part1(r io.Reader) int {
 		sum = 0
 	}
 
-	return best
+	return aoc22.Int(best), nil
 }
 
-func Part2(r io.Reader) int {
+func Part2(ctx context.Context, r io.Reader) (aoc22.Answer, error) {
+	groups, err := parse(r)
+	if err != nil {
+		return aoc22.Answer{}, err
+	}
+
 	var sums []int
 
-	for _, group := range parse(r) {
+	for _, group := range groups {
 		var sum int
 		for _, n := range group {
 			sum += n
@@ -62,7 +80,19 @@ func Part2(r io.Reader) int {
 		sums = append(sums, sum)
 	}
 
+	if len(sums) < 3 {
+		return aoc22.Answer{}, fmt.Errorf("need at least 3 elves, got %d", len(sums))
+	}
+
 	slices.Sort(sums)
 
-	return sums[len(sums)-1] + sums[len(sums)-2] + sums[len(sums)-3]
+	return aoc22.Int(sums[len(sums)-1] + sums[len(sums)-2] + sums[len(sums)-3]), nil
+}
+
+func init() {
+	aoc22.Register(aoc22.Puzzle{
+		Day:   1,
+		Part1: func(ctx context.Context, r io.Reader, _ aoc22.Params) (aoc22.Answer, error) { return Part1(ctx, r) },
+		Part2: func(ctx context.Context, r io.Reader, _ aoc22.Params) (aoc22.Answer, error) { return Part2(ctx, r) },
+	})
 }
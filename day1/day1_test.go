@@ -2,29 +2,52 @@ package day1
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/clfs/aoc22"
 )
 
 func TestPart1(t *testing.T) {
-	data := aoc22.ReadTestFile(t, "testdata/input.txt")
+	aoc22.CheckAnswers(t, 1, 1)
+}
 
-	got := Part1(bytes.NewReader(data))
-	want := 71924
+func TestPart2(t *testing.T) {
+	aoc22.CheckAnswers(t, 1, 2)
+}
 
-	if got != want {
-		t.Errorf("Part1() = %d, want %d", got, want)
+func TestParse_Error(t *testing.T) {
+	_, err := Part1(context.Background(), strings.NewReader("1000\n2000\n\n3x00\n"))
+
+	var pe *aoc22.ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("Part1() error = %v, want a ParseError", err)
+	}
+	if pe.Day != 1 || pe.Line != 4 || pe.Text != "3x00" {
+		t.Errorf("Part1() error = %v, want day 1, line 4, text \"3x00\"", pe)
 	}
 }
 
-func TestPart2(t *testing.T) {
-	data := aoc22.ReadTestFile(t, "testdata/input.txt")
-
-	got := Part2(bytes.NewReader(data))
-	want := 210406
+func BenchmarkPart1(b *testing.B) {
+	data := aoc22.ReadTestFile(b, "testdata/input.txt")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Part1(context.Background(), bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
 
-	if got != want {
-		t.Errorf("Part2() = %d, want %d", got, want)
+func BenchmarkPart2(b *testing.B) {
+	data := aoc22.ReadTestFile(b, "testdata/input.txt")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Part2(context.Background(), bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
 	}
 }
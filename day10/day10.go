@@ -2,11 +2,17 @@ package day10
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"strconv"
 	"strings"
+
+	"github.com/clfs/aoc22"
+	"github.com/clfs/aoc22/anim"
+	"github.com/clfs/aoc22/geom"
+	"github.com/clfs/aoc22/grid"
 )
 
 type Op struct {
@@ -64,7 +70,13 @@ type CPU struct {
 
 	cycle  int
 	sprite int
-	crt    [][]bool
+	crt    *grid.Grid[bool]
+
+	Tracer aoc22.Tracer // If set, receives an event for every step of every cycle.
+
+	// If set, gets a frame for every cycle, showing the CRT with the beam on
+	// the pixel being drawn.
+	Anim *anim.Recorder
 }
 
 func (c *CPU) Load(p Program) {
@@ -77,9 +89,12 @@ func (c *CPU) Load(p Program) {
 
 	c.cycle = 1
 	c.sprite = 1
-	c.crt = make([][]bool, CRTHeight)
-	for i := range c.crt {
-		c.crt[i] = make([]bool, CRTWidth)
+	c.crt = grid.New[bool](CRTWidth, CRTHeight)
+
+	if c.Anim != nil {
+		// Fix the picture to the size of the screen.
+		c.Anim.Set(geom.Pt(0, 0), anim.Background)
+		c.Anim.Set(geom.Pt(CRTWidth-1, CRTHeight-1), anim.Background)
 	}
 }
 
@@ -93,8 +108,6 @@ func (c *CPU) Tick() int {
 }
 
 func (c *CPU) start() {
-	log.Printf("sprite position: %d", c.sprite)
-
 	// Instructions are only started if we're not waiting for any to complete.
 	if c.executing != nil {
 		return
@@ -103,7 +116,12 @@ func (c *CPU) start() {
 	// Start the next instruction.
 	c.executing = &c.p[c.pc]
 
-	log.Printf("start cycle %d: begin executing %v", c.cycle, c.executing)
+	if c.Tracer != nil {
+		c.Tracer.Trace("cpu.start",
+			slog.Int("cycle", c.cycle),
+			slog.String("op", c.executing.Name),
+			slog.Int("arg", c.executing.Arg))
+	}
 
 	switch c.executing.Name {
 	case "noop":
@@ -120,34 +138,52 @@ func (c *CPU) during() int {
 
 	spriteCol := c.sprite % CRTWidth
 
-	log.Printf("during cycle %d: crt drawing pixel (%d, %d)", c.cycle, row, col)
+	lit := col == spriteCol || col == spriteCol-1 || col == spriteCol+1
+	if lit {
+		c.crt.Set(geom.Pt(col, row), true)
+	}
 
-	if col == spriteCol {
-		c.crt[row][col] = true
-	} else if col == spriteCol-1 {
-		c.crt[row][col] = true
-	} else if col == spriteCol+1 {
-		c.crt[row][col] = true
+	if c.Anim != nil {
+		c.drawBeam(geom.Pt(col, row))
 	}
 
-	log.Printf("current crt:\n%s", c.Render())
+	if c.Tracer != nil {
+		c.Tracer.Trace("crt.draw",
+			slog.Int("cycle", c.cycle),
+			slog.Int("row", row),
+			slog.Int("col", col),
+			slog.Int("sprite", c.sprite),
+			slog.Bool("lit", lit))
+	}
 
 	return c.x
 }
 
+// drawBeam draws the CRT on c.Anim with the beam at p, and ends the frame.
+func (c *CPU) drawBeam(p geom.Point) {
+	if c.cycle > 1 {
+		c.drawPixel(geom.Pt((c.cycle-2)%CRTWidth, (c.cycle-2)/CRTWidth))
+	}
+	c.Anim.Set(p, anim.White)
+	c.Anim.Frame()
+}
+
+// drawPixel draws the CRT pixel at p on c.Anim.
+func (c *CPU) drawPixel(p geom.Point) {
+	if c.crt.At(p) {
+		c.Anim.Set(p, anim.Green)
+	} else {
+		c.Anim.Set(p, anim.Background)
+	}
+}
+
 func (c *CPU) Render() string {
-	var b strings.Builder
-	for _, row := range c.crt {
-		for _, pixel := range row {
-			if pixel {
-				b.WriteRune('#')
-			} else {
-				b.WriteRune('.')
-			}
+	return c.crt.Render(func(pixel bool) rune {
+		if pixel {
+			return '#'
 		}
-		b.WriteRune('\n')
-	}
-	return b.String()
+		return '.'
+	})
 }
 
 func (c *CPU) after() {
@@ -161,7 +197,12 @@ func (c *CPU) after() {
 			c.x += c.executing.Arg
 		}
 
-		log.Printf("end of cycle %d: finish executing %v (Register X is now %d)", c.cycle, c.executing, c.x)
+		if c.Tracer != nil {
+			c.Tracer.Trace("cpu.finish",
+				slog.Int("cycle", c.cycle),
+				slog.String("op", c.executing.Name),
+				slog.Int("x", c.x))
+		}
 
 		// Clear the executing instruction.
 		c.executing = nil
@@ -172,13 +213,13 @@ func (c *CPU) after() {
 	c.sprite = c.x
 }
 
-func Part1(r io.Reader) (int, error) {
+func Part1(ctx context.Context, r io.Reader) (aoc22.Answer, error) {
 	program, err := ParseProgram(r)
 	if err != nil {
-		return 0, err
+		return aoc22.Answer{}, err
 	}
 
-	var cpu CPU
+	cpu := CPU{Tracer: aoc22.TracerFrom(ctx)}
 	cpu.Load(program)
 
 	var sum int
@@ -188,7 +229,7 @@ func Part1(r io.Reader) (int, error) {
 			sum += i * x
 		}
 	}
-	return sum, nil
+	return aoc22.Int(sum), nil
 }
 
 const (
@@ -196,17 +237,62 @@ const (
 	CRTWidth  = 40
 )
 
-func Part2(r io.Reader) (string, error) {
+func Part2(ctx context.Context, r io.Reader) (aoc22.Answer, error) {
 	program, err := ParseProgram(r)
 	if err != nil {
-		return "", err
+		return aoc22.Answer{}, err
 	}
 
-	var cpu CPU
+	cpu := CPU{Tracer: aoc22.TracerFrom(ctx), Anim: anim.RecorderFrom(ctx)}
 	cpu.Load(program)
 
 	for i := 1; i <= CRTWidth*CRTHeight; i++ {
 		cpu.Tick()
 	}
-	return cpu.Render(), nil
+	if cpu.Anim != nil {
+		// Take the beam off the last pixel.
+		cpu.drawPixel(geom.Pt(CRTWidth-1, CRTHeight-1))
+		cpu.Anim.Frame()
+	}
+	return aoc22.Text(cpu.Render()), nil
+}
+
+// Simulate starts a simulation of the CPU drawing on the CRT, one cycle per
+// step. It's the same for both parts.
+func Simulate(part int, r io.Reader) (aoc22.Simulation, error) {
+	program, err := ParseProgram(r)
+	if err != nil {
+		return nil, err
+	}
+	s := new(simulation)
+	s.cpu.Load(program)
+	return s, nil
+}
+
+type simulation struct {
+	cpu CPU
+}
+
+func (s *simulation) Step() bool {
+	c := &s.cpu
+	if c.cycle > CRTWidth*CRTHeight || (c.executing == nil && c.pc >= len(c.p)) {
+		return false
+	}
+	c.Tick()
+	return true
+}
+
+func (s *simulation) Render() string {
+	return fmt.Sprintf("cycle %d, X = %d\n\n%s", s.cpu.cycle-1, s.cpu.x, s.cpu.Render())
+}
+
+func init() {
+	aoc22.Register(aoc22.Puzzle{
+		Day:   10,
+		Part1: func(ctx context.Context, r io.Reader, _ aoc22.Params) (aoc22.Answer, error) { return Part1(ctx, r) },
+		Part2: func(ctx context.Context, r io.Reader, _ aoc22.Params) (aoc22.Answer, error) { return Part2(ctx, r) },
+		Simulate: func(part int, r io.Reader, _ aoc22.Params) (aoc22.Simulation, error) {
+			return Simulate(part, r)
+		},
+	})
 }
@@ -1,8 +1,12 @@
 package day10
 
 import (
+	"bytes"
+	"context"
 	"os"
 	"testing"
+
+	"github.com/clfs/aoc22"
 )
 
 func TestOp_UnmarshalText(t *testing.T) {
@@ -67,64 +71,31 @@ func TestCPU_Tick(t *testing.T) {
 }
 
 func TestPart1(t *testing.T) {
-	cases := []struct {
-		name string
-		want int
-	}{
-		{"testdata/large.txt", 13140},
-		{"testdata/input.txt", 15220},
-	}
-
-	for _, tc := range cases {
-		t.Run(tc.name, func(t *testing.T) {
-			f, err := os.Open(tc.name)
-			if err != nil {
-				t.Fatal(err)
-			}
-			defer f.Close()
-
-			got, err := Part1(f)
-			if err != nil {
-				t.Errorf("Part1() error: %v", err)
-			}
-			if got != tc.want {
-				t.Errorf("Part1() = %v, want %v", got, tc.want)
-			}
-		})
-	}
+	aoc22.CheckAnswers(t, 10, 1)
 }
 
 func TestPart2(t *testing.T) {
-	cases := []struct {
-		name string
-		want string
-	}{
-		{"testdata/large.txt", "testdata/large_crt.txt"},
-		{"testdata/input.txt", "testdata/input_crt.txt"},
-	}
-
-	for _, tc := range cases {
-		t.Run(tc.name, func(t *testing.T) {
-			f, err := os.Open(tc.name)
-			if err != nil {
-				t.Fatal(err)
-			}
-			defer f.Close()
-
-			got, err := Part2(f)
-			if err != nil {
-				t.Errorf("Part2() error: %v", err)
-			}
-
-			want, err := os.ReadFile(tc.want)
-			if err != nil {
-				t.Fatal(err)
-			}
+	aoc22.CheckAnswers(t, 10, 2)
+}
 
-			if got != string(want) {
-				t.Errorf("Part2() mismatch:\ngot:\n%v\nwant:\n%s", got, want)
-			}
-		})
+func BenchmarkPart1(b *testing.B) {
+	data := aoc22.ReadTestFile(b, "testdata/input.txt")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Part1(context.Background(), bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
 	}
+}
 
+func BenchmarkPart2(b *testing.B) {
+	data := aoc22.ReadTestFile(b, "testdata/input.txt")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Part2(context.Background(), bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
 }
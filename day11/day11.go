@@ -2,13 +2,19 @@ package day11
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
-	"log"
-	"regexp"
+	"log/slog"
+	"math"
+	"math/rand"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+
+	"github.com/clfs/aoc22"
 )
 
 type Monkey struct {
@@ -18,58 +24,78 @@ type Monkey struct {
 	Pass, Fail int
 }
 
-func ParseOperation(s string) func(int) int {
+// ParseOperation parses the right-hand side of a monkey's operation, without
+// the leading "old", like "+ 12" or "* old".
+func ParseOperation(s string) (func(int) int, error) {
 	if s == "* old" {
-		return func(old int) int { return old * old }
+		return func(old int) int { return old * old }, nil
 	}
 
-	if s[0] == '*' {
-		n, err := strconv.Atoi(s[2:])
-		if err != nil {
-			panic(fmt.Sprintf("bad operation: %q", s))
-		}
-		return func(old int) int { return old * n }
+	op, arg, ok := strings.Cut(s, " ")
+	if !ok {
+		return nil, fmt.Errorf("bad operation: %q", s)
 	}
 
-	if s[0] == '+' {
-		n, err := strconv.Atoi(s[2:])
-		if err != nil {
-			panic(fmt.Sprintf("bad operation: %q", s))
-		}
-		return func(old int) int { return old + n }
+	n, err := strconv.Atoi(arg)
+	if err != nil {
+		return nil, fmt.Errorf("bad operation: %q: %w", s, err)
 	}
 
-	panic(fmt.Sprintf("bad operation: %q", s))
+	switch op {
+	case "*":
+		return func(old int) int { return old * n }, nil
+	case "+":
+		return func(old int) int { return old + n }, nil
+	default:
+		return nil, fmt.Errorf("bad operation: %q", s)
+	}
+}
+
+// monkeyLines holds the prefix of each line of a monkey's description.
+var monkeyLines = []string{
+	"Monkey ",
+	"  Starting items:",
+	"  Operation: new = old ",
+	"  Test: divisible by ",
+	"    If true: throw to monkey ",
+	"    If false: throw to monkey ",
 }
 
 func (m *Monkey) UnmarshalText(text []byte) error {
 	lines := strings.Split(string(text), "\n")
+	if len(lines) < len(monkeyLines) {
+		return fmt.Errorf("monkey has %d lines, want %d", len(lines), len(monkeyLines))
+	}
+	for i, prefix := range monkeyLines {
+		if !strings.HasPrefix(lines[i], prefix) {
+			return &aoc22.ParseError{Line: i + 1, Column: 1, Text: lines[i], Err: fmt.Errorf("want prefix %q", prefix)}
+		}
+	}
 
-	m.Items = ReadNumbers(lines[1])
+	var err error
+	m.Items, err = aoc22.ReadInts(lines[1])
+	if err != nil {
+		return aoc22.NewParseError(0, 2, lines[1], err)
+	}
 
-	m.Operation = ParseOperation(
+	m.Operation, err = ParseOperation(
 		// just the "+ 12" bit
-		strings.TrimPrefix(lines[2], "  Operation: new = old "))
-
-	m.Divisor = ReadNumbers(lines[3])[0]
-	m.Pass = ReadNumbers(lines[4])[0]
-	m.Fail = ReadNumbers(lines[5])[0]
-
-	return nil
-}
-
-var numbersRe = regexp.MustCompile(`\d+`)
+		strings.TrimPrefix(lines[2], monkeyLines[2]))
+	if err != nil {
+		return &aoc22.ParseError{Line: 3, Column: len(monkeyLines[2]) + 1, Text: lines[2], Err: err}
+	}
 
-func ReadNumbers(s string) []int {
-	var result []int
-	for _, num := range numbersRe.FindAllString(s, -1) {
-		n, err := strconv.Atoi(num)
+	// The last three lines each end with a single number.
+	for i, dst := range []*int{&m.Divisor, &m.Pass, &m.Fail} {
+		line, prefix := lines[i+3], monkeyLines[i+3]
+		n, err := strconv.Atoi(strings.TrimPrefix(line, prefix))
 		if err != nil {
-			panic(fmt.Sprintf("bad number: %q", num))
+			return &aoc22.ParseError{Line: i + 4, Column: len(prefix) + 1, Text: line, Err: err}
 		}
-		result = append(result, n)
+		*dst = n
 	}
-	return result
+
+	return nil
 }
 
 func Parse(r io.Reader) ([]Monkey, error) {
@@ -81,30 +107,46 @@ func Parse(r io.Reader) ([]Monkey, error) {
 	texts := bytes.Split(data, []byte("\n\n"))
 
 	var result []Monkey
+	line := 1 // The line each monkey starts on.
 	for _, text := range texts {
 		var m Monkey
 		if err := m.UnmarshalText(text); err != nil {
-			return nil, err
+			var pe *aoc22.ParseError
+			if errors.As(err, &pe) && pe.Line != 0 {
+				moved := *pe
+				moved.Day = 11
+				moved.Line += line - 1
+				return nil, &moved
+			}
+			first, _, _ := bytes.Cut(text, []byte("\n"))
+			return nil, aoc22.NewParseError(11, line, string(first), err)
 		}
 		result = append(result, m)
+		line += bytes.Count(text, []byte("\n")) + 2
+	}
+
+	for i, m := range result {
+		for _, target := range []int{m.Pass, m.Fail} {
+			if target < 0 || target >= len(result) {
+				return nil, &aoc22.ParseError{Day: 11, Err: fmt.Errorf("monkey %d throws to missing monkey %d", i, target)}
+			}
+		}
 	}
 
 	return result, nil
 }
 
-func Part1(r io.Reader) (int, error) {
+func Part1(ctx context.Context, r io.Reader) (aoc22.Answer, error) {
 	monkeys, err := Parse(r)
 	if err != nil {
-		return 0, err
+		return aoc22.Answer{}, err
 	}
 
+	tr := aoc22.TracerFrom(ctx)
 	nInspections := make(map[int]int)
 
 	for i := 0; i < 20; i++ {
-		log.Printf("==== round %d", i)
-		for j, m := range monkeys {
-			log.Printf("Monkey %d: %v", j, m.Items)
-		}
+		traceRound(tr, i, monkeys)
 
 		for j, m := range monkeys {
 			// Monkey inspects each item in its list.
@@ -128,10 +170,33 @@ func Part1(r io.Reader) (int, error) {
 		}
 	}
 
-	log.Print(nInspections)
+	traceInspections(tr, nInspections)
 
 	values := SortedValues(nInspections)
-	return values[len(values)-1] * values[len(values)-2], nil
+	return aoc22.Int(values[len(values)-1] * values[len(values)-2]), nil
+}
+
+// traceRound traces the items each monkey holds at the start of a round.
+func traceRound(tr aoc22.Tracer, round int, monkeys []Monkey) {
+	if tr == nil {
+		return
+	}
+	for i, m := range monkeys {
+		tr.Trace("monkey.items",
+			slog.Int("round", round),
+			slog.Int("monkey", i),
+			slog.Any("items", slices.Clone(m.Items)))
+	}
+}
+
+// traceInspections traces how many items each monkey inspected.
+func traceInspections(tr aoc22.Tracer, n map[int]int) {
+	if tr == nil {
+		return
+	}
+	for i := 0; i < len(n); i++ {
+		tr.Trace("monkey.inspections", slog.Int("monkey", i), slog.Int("count", n[i]))
+	}
 }
 
 // input:
@@ -147,10 +212,10 @@ func SortedValues(m map[int]int) []int {
 	return result
 }
 
-func Part2(r io.Reader) (int, error) {
+func Part2(ctx context.Context, r io.Reader) (aoc22.Answer, error) {
 	monkeys, err := Parse(r)
 	if err != nil {
-		return 0, err
+		return aoc22.Answer{}, err
 	}
 
 	megaMod := 1
@@ -158,13 +223,14 @@ func Part2(r io.Reader) (int, error) {
 		megaMod *= m.Divisor
 	}
 
+	tr := aoc22.TracerFrom(ctx)
 	nInspections := make(map[int]int)
 
 	for i := 0; i < 10000; i++ {
-		log.Printf("==== round %d", i)
-		for j, m := range monkeys {
-			log.Printf("Monkey %d: %v", j, m.Items)
+		if err := ctx.Err(); err != nil {
+			return aoc22.Answer{}, err
 		}
+		traceRound(tr, i, monkeys)
 
 		for j := range monkeys {
 			// Monkey inspects each item in its list.
@@ -188,11 +254,10 @@ func Part2(r io.Reader) (int, error) {
 		}
 	}
 
-	log.Print(megaMod)
-	log.Print(nInspections)
+	traceInspections(tr, nInspections)
 
 	values := SortedValues(nInspections)
-	return values[len(values)-1] * values[len(values)-2], nil
+	return aoc22.Int(values[len(values)-1] * values[len(values)-2]), nil
 }
 
 /*
@@ -213,3 +278,163 @@ n*n = 16 = 0 (mod 8)
 
 
 */
+
+// A genMonkey is a monkey in a generated input.
+type genMonkey struct {
+	items      []int
+	mul        bool // Whether the operation multiplies rather than adds.
+	arg        int  // The operation's argument, or 0 for "old".
+	divisor    int
+	pass, fail int
+}
+
+func (m *genMonkey) apply(old int) (int, bool) {
+	arg := m.arg
+	if arg == 0 {
+		arg = old
+	}
+	if m.mul {
+		return old * arg, arg == 0 || old <= math.MaxInt/arg
+	}
+	return old + arg, old <= math.MaxInt-arg
+}
+
+// genRounds plays the 20 rounds of part 1 on copies of ms, and returns the
+// monkey business. It returns false if a worry level overflows an int.
+func genRounds(ms []genMonkey) (int, bool) {
+	items := make([][]int, len(ms))
+	for i, m := range ms {
+		items[i] = slices.Clone(m.items)
+	}
+
+	counts := make([]int, len(ms))
+	for round := 0; round < 20; round++ {
+		for i := range ms {
+			m := &ms[i]
+			for _, item := range items[i] {
+				worry, ok := m.apply(item)
+				if !ok {
+					return 0, false
+				}
+				worry /= 3
+				counts[i]++
+				if worry%m.divisor == 0 {
+					items[m.pass] = append(items[m.pass], worry)
+				} else {
+					items[m.fail] = append(items[m.fail], worry)
+				}
+			}
+			items[i] = nil
+		}
+	}
+
+	sort.Sort(sort.Reverse(sort.IntSlice(counts)))
+	return counts[0] * counts[1], true
+}
+
+// genPrimes are the divisors of generated monkeys. Their product is small
+// enough that squaring a worry level below it never overflows, so part 2
+// stays solvable.
+var genPrimes = []int{2, 3, 5, 7, 11, 13, 17, 19}
+
+// Generate writes between 2 and 8 monkeys holding size items between them.
+// Only the answer to part 1 is known; it comes from checking that no worry
+// level in part 1 overflows.
+func Generate(w io.Writer, rng *rand.Rand, size int) (aoc22.Generated, error) {
+	if size == 0 {
+		size = 36
+	}
+	if size < 2 {
+		return aoc22.Generated{}, fmt.Errorf("invalid size %d: need at least 2 items", size)
+	}
+
+	for try := 0; try < 100; try++ {
+		ms := genMonkeys(rng, size)
+		business, ok := genRounds(ms)
+		if !ok {
+			continue
+		}
+
+		var buf bytes.Buffer
+		for i, m := range ms {
+			if i > 0 {
+				buf.WriteString("\n")
+			}
+			op := fmt.Sprintf("+ %d", m.arg)
+			switch {
+			case m.arg == 0:
+				op = "* old"
+			case m.mul:
+				op = fmt.Sprintf("* %d", m.arg)
+			}
+			items := make([]string, len(m.items))
+			for j, item := range m.items {
+				items[j] = strconv.Itoa(item)
+			}
+			fmt.Fprintf(&buf, "Monkey %d:\n", i)
+			fmt.Fprintf(&buf, "  Starting items: %s\n", strings.Join(items, ", "))
+			fmt.Fprintf(&buf, "  Operation: new = old %s\n", op)
+			fmt.Fprintf(&buf, "  Test: divisible by %d\n", m.divisor)
+			fmt.Fprintf(&buf, "    If true: throw to monkey %d\n", m.pass)
+			fmt.Fprintf(&buf, "    If false: throw to monkey %d\n", m.fail)
+		}
+		if _, err := buf.WriteTo(w); err != nil {
+			return aoc22.Generated{}, err
+		}
+		return aoc22.Generated{Answers: [2]aoc22.Answer{aoc22.Int(business)}}, nil
+	}
+
+	return aoc22.Generated{}, errors.New("every generated input overflowed")
+}
+
+// genMonkeys returns random monkeys holding n items, with worry levels like
+// the ones in real inputs. Every monkey holds at least one item, and exactly
+// one squares its items.
+func genMonkeys(rng *rand.Rand, n int) []genMonkey {
+	ms := make([]genMonkey, min(n, 2+rng.Intn(len(genPrimes)-1)))
+	divisors := rng.Perm(len(genPrimes))
+	squarer := rng.Intn(len(ms))
+
+	for i := range ms {
+		m := &ms[i]
+		m.divisor = genPrimes[divisors[i]]
+
+		// Throw to two other monkeys, which are the same one if there
+		// are only two monkeys.
+		others := rng.Perm(len(ms) - 1)
+		m.pass, m.fail = others[0], others[len(others)-1]
+		if m.pass >= i {
+			m.pass++
+		}
+		if m.fail >= i {
+			m.fail++
+		}
+
+		switch {
+		case i == squarer:
+			m.mul = true
+		case rng.Intn(3) == 0:
+			m.mul, m.arg = true, 2+rng.Intn(18)
+		default:
+			m.arg = 1 + rng.Intn(8)
+		}
+	}
+
+	for i := 0; i < n; i++ {
+		m := &ms[i%len(ms)]
+		if i >= len(ms) {
+			m = &ms[rng.Intn(len(ms))]
+		}
+		m.items = append(m.items, 50+rng.Intn(50))
+	}
+	return ms
+}
+
+func init() {
+	aoc22.Register(aoc22.Puzzle{
+		Day:      11,
+		Part1:    func(ctx context.Context, r io.Reader, _ aoc22.Params) (aoc22.Answer, error) { return Part1(ctx, r) },
+		Part2:    func(ctx context.Context, r io.Reader, _ aoc22.Params) (aoc22.Answer, error) { return Part2(ctx, r) },
+		Generate: Generate,
+	})
+}
@@ -1,8 +1,14 @@
 package day11
 
 import (
-	"os"
+	"bytes"
+	"context"
+	"errors"
+	"math/rand"
+	"strings"
 	"testing"
+
+	"github.com/clfs/aoc22"
 )
 
 func TestParseOperation(t *testing.T) {
@@ -16,7 +22,11 @@ func TestParseOperation(t *testing.T) {
 	}
 
 	for _, c := range cases {
-		got := ParseOperation(c.in)
+		got, err := ParseOperation(c.in)
+		if err != nil {
+			t.Errorf("ParseOperation(%q) error: %v", c.in, err)
+			continue
+		}
 		for i := 0; i < 3; i++ {
 			if got(i) != c.want(i) {
 				t.Errorf("ParseOperation(%q)(%d) = %d, want %d", c.in, i, got(i), c.want(i))
@@ -26,57 +36,77 @@ func TestParseOperation(t *testing.T) {
 }
 
 func TestPart1(t *testing.T) {
-	cases := []struct {
-		name string
-		want int
-	}{
-		{"testdata/small.txt", 10605},
-		{"testdata/input.txt", 76728},
+	aoc22.CheckAnswers(t, 11, 1)
+}
+
+func TestPart2(t *testing.T) {
+	aoc22.CheckAnswers(t, 11, 2)
+}
+
+func TestParse_Error(t *testing.T) {
+	monkey := func(op string) string {
+		return "Monkey 0:\n" +
+			"  Starting items: 79, 98\n" +
+			"  Operation: new = old " + op + "\n" +
+			"  Test: divisible by 23\n" +
+			"    If true: throw to monkey 1\n" +
+			"    If false: throw to monkey 0\n"
 	}
 
-	for _, tc := range cases {
-		t.Run(tc.name, func(t *testing.T) {
-			f, err := os.Open(tc.name)
-			if err != nil {
-				t.Fatal(err)
-			}
-			defer f.Close()
+	in := monkey("* 19") + "\n" + monkey("^ 2")
 
-			got, err := Part1(f)
-			if err != nil {
-				t.Fatal(err)
-			}
-			if got != tc.want {
-				t.Errorf("Part1() = %d, want %d", got, tc.want)
-			}
-		})
+	_, err := Parse(strings.NewReader(in))
+
+	var pe *aoc22.ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("Parse() error = %v, want a ParseError", err)
+	}
+	if pe.Day != 11 || pe.Line != 10 || pe.Column != 24 {
+		t.Errorf("Parse() error = %v, want day 11 at 10:24", pe)
+	}
+
+	if _, err := ParseOperation("- 3"); err == nil {
+		t.Errorf("ParseOperation(%q) succeeded", "- 3")
 	}
 }
 
-func TestPart2(t *testing.T) {
-	cases := []struct {
-		name string
-		want int
-	}{
-		{"testdata/small.txt", 2713310158},
-		{"testdata/input.txt", 21553910156},
+func TestGenerate(t *testing.T) {
+	for seed := int64(1); seed <= 10; seed++ {
+		var buf bytes.Buffer
+		g, err := Generate(&buf, rand.New(rand.NewSource(seed)), int(seed)*5)
+		if err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+
+		want, _ := g.Answer(1)
+		got, err := Part1(context.Background(), bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+		if got != want {
+			t.Errorf("seed %d: got %v, want %v", seed, got, want)
+		}
 	}
+}
 
-	for _, tc := range cases {
-		t.Run(tc.name, func(t *testing.T) {
-			f, err := os.Open(tc.name)
-			if err != nil {
-				t.Fatal(err)
-			}
-			defer f.Close()
+func BenchmarkPart1(b *testing.B) {
+	data := aoc22.ReadTestFile(b, "testdata/input.txt")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Part1(context.Background(), bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
 
-			got, err := Part2(f)
-			if err != nil {
-				t.Fatal(err)
-			}
-			if got != tc.want {
-				t.Errorf("Part1() = %d, want %d", got, tc.want)
-			}
-		})
+func BenchmarkPart2(b *testing.B) {
+	data := aoc22.ReadTestFile(b, "testdata/input.txt")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Part2(context.Background(), bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
 	}
 }
@@ -1,72 +1,69 @@
 package day12
 
 import (
-	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"io"
+
+	"github.com/clfs/aoc22"
+	"github.com/clfs/aoc22/geom"
+	"github.com/clfs/aoc22/grid"
 )
 
-func ToHeight(r rune) int {
+// ToHeight returns the height of a square, from 0 for 'a' (and the start, 'S')
+// up to 25 for 'z' (and the goal, 'E').
+func ToHeight(r rune) (int, error) {
 	switch {
 	case 'a' <= r && r <= 'z':
-		return int(r - 'a')
+		return int(r - 'a'), nil
 	case r == 'S':
 		return ToHeight('a')
 	case r == 'E':
 		return ToHeight('z')
 	default:
-		panic(fmt.Sprintf("bad rune: %c", r))
+		return 0, fmt.Errorf("bad rune: %q", r)
 	}
 }
 
-type Point struct {
-	X, Y int
-}
-
 type Topo struct {
-	Grid        [][]int
-	Start, Goal Point
+	Grid        *grid.Grid[int]
+	Start, Goal geom.Point
 }
 
 func Parse(r io.Reader) (Topo, error) {
 	var topo Topo
 
-	s := bufio.NewScanner(r)
-	for s.Scan() {
-		line := s.Text()
-		row := make([]int, len(line))
-		for i, r := range line {
-			row[i] = ToHeight(r)
-			if r == 'S' {
-				topo.Start = Point{X: i, Y: len(topo.Grid)}
-			}
-			if r == 'E' {
-				topo.Goal = Point{X: i, Y: len(topo.Grid)}
-			}
+	g, err := grid.Parse(r, func(p geom.Point, r rune) (int, error) {
+		switch r {
+		case 'S':
+			topo.Start = p
+		case 'E':
+			topo.Goal = p
+		}
+		return ToHeight(r)
+	})
+	if err != nil {
+		var pe *aoc22.ParseError
+		if errors.As(err, &pe) {
+			pe.Day = 12
 		}
-		topo.Grid = append(topo.Grid, row)
+		return Topo{}, err
 	}
 
-	return topo, s.Err()
+	topo.Grid = g
+	return topo, nil
 }
 
-func (t *Topo) At(p Point) int {
-	return t.Grid[p.Y][p.X]
+func (t *Topo) At(p geom.Point) int {
+	return t.Grid.At(p)
 }
 
-func (t *Topo) Neighbors(p Point) []Point {
-	var neighbors []Point
-	for _, d := range []Point{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
-		np := Point{X: p.X + d.X, Y: p.Y + d.Y}
-		if 0 <= np.X && np.X < len(t.Grid[0]) &&
-			0 <= np.Y && np.Y < len(t.Grid) {
-			neighbors = append(neighbors, np)
-		}
-	}
-	return neighbors
+func (t *Topo) Neighbors(p geom.Point) []geom.Point {
+	return t.Grid.Neighbors4(p)
 }
 
-func (t *Topo) CanMove(from, to Point) bool {
+func (t *Topo) CanMove(from, to geom.Point) bool {
 	var found bool
 	neighbors := t.Neighbors(from)
 	for _, n := range neighbors {
@@ -79,9 +76,9 @@ func (t *Topo) CanMove(from, to Point) bool {
 }
 
 func (t *Topo) LengthOfShortestPath() (int, error) {
-	seen := make(map[Point]int)
+	seen := make(map[geom.Point]int)
 	queue := []struct {
-		p Point
+		p geom.Point
 		n int
 	}{{t.Start, 0}}
 
@@ -109,7 +106,7 @@ func (t *Topo) LengthOfShortestPath() (int, error) {
 		for _, np := range t.Neighbors(head.p) {
 			if t.CanMove(head.p, np) {
 				queue = append(queue, struct {
-					p Point
+					p geom.Point
 					n int
 				}{np, head.n + 1})
 			}
@@ -119,37 +116,49 @@ func (t *Topo) LengthOfShortestPath() (int, error) {
 	return 0, fmt.Errorf("no path exists between %v and %v", t.Start, t.Goal)
 }
 
-func Part1(r io.Reader) (int, error) {
+func Part1(ctx context.Context, r io.Reader) (aoc22.Answer, error) {
 	topo, err := Parse(r)
 	if err != nil {
-		return 0, err
+		return aoc22.Answer{}, err
+	}
+	n, err := topo.LengthOfShortestPath()
+	if err != nil {
+		return aoc22.Answer{}, err
 	}
-	return topo.LengthOfShortestPath()
+	return aoc22.Int(n), nil
 }
 
-func Part2(r io.Reader) (int, error) {
+func Part2(ctx context.Context, r io.Reader) (aoc22.Answer, error) {
 	topo, err := Parse(r)
 	if err != nil {
-		return 0, err
+		return aoc22.Answer{}, err
 	}
 
 	var best int
 
-	for x := 0; x < len(topo.Grid[0]); x++ {
-		for y := 0; y < len(topo.Grid); y++ {
-			p := Point{X: x, Y: y}
-			if topo.At(p) == ToHeight('a') {
-				topo.Start = p
-				n, err := topo.LengthOfShortestPath()
-				if err != nil {
-					continue // no path exists
-				}
-				if best == 0 || n < best {
-					best = n
-				}
+	for _, p := range topo.Grid.Points() {
+		if topo.At(p) == 0 { // 'a' or 'S'
+			if err := ctx.Err(); err != nil {
+				return aoc22.Int(best), err // The shortest path so far.
+			}
+			topo.Start = p
+			n, err := topo.LengthOfShortestPath()
+			if err != nil {
+				continue // no path exists
+			}
+			if best == 0 || n < best {
+				best = n
 			}
 		}
 	}
 
-	return best, nil
+	return aoc22.Int(best), nil
+}
+
+func init() {
+	aoc22.Register(aoc22.Puzzle{
+		Day:   12,
+		Part1: func(ctx context.Context, r io.Reader, _ aoc22.Params) (aoc22.Answer, error) { return Part1(ctx, r) },
+		Part2: func(ctx context.Context, r io.Reader, _ aoc22.Params) (aoc22.Answer, error) { return Part2(ctx, r) },
+	})
 }
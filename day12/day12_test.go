@@ -1,36 +1,19 @@
 package day12
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"os"
+	"strings"
 	"testing"
+
+	"github.com/clfs/aoc22"
+	"github.com/clfs/aoc22/geom"
 )
 
 func TestPart1(t *testing.T) {
-	cases := []struct {
-		name string
-		want int
-	}{
-		{"testdata/small.txt", 31},
-		{"testdata/input.txt", 497},
-	}
-
-	for _, tc := range cases {
-		t.Run(tc.name, func(t *testing.T) {
-			f, err := os.Open(tc.name)
-			if err != nil {
-				t.Fatal(err)
-			}
-			defer f.Close()
-
-			got, err := Part1(f)
-			if err != nil {
-				t.Errorf("error: %v", err)
-			}
-			if got != tc.want {
-				t.Errorf("%v, want %v", got, tc.want)
-			}
-		})
-	}
+	aoc22.CheckAnswers(t, 12, 1)
 }
 
 func readTopo(t *testing.T, name string) *Topo {
@@ -50,12 +33,12 @@ func readTopo(t *testing.T, name string) *Topo {
 func TestCanMove(t *testing.T) {
 	topo := readTopo(t, "testdata/small.txt")
 	cases := []struct {
-		from, to Point
+		from, to geom.Point
 		want     bool
 	}{
-		{Point{0, 0}, Point{0, 1}, true},
-		{Point{0, 0}, Point{1, 0}, true},
-		{Point{0, 0}, Point{1, 1}, false},
+		{geom.Pt(0, 0), geom.Pt(0, 1), true},
+		{geom.Pt(0, 0), geom.Pt(1, 0), true},
+		{geom.Pt(0, 0), geom.Pt(1, 1), false},
 	}
 
 	for _, tc := range cases {
@@ -66,29 +49,50 @@ func TestCanMove(t *testing.T) {
 }
 
 func TestPart2(t *testing.T) {
+	aoc22.CheckAnswers(t, 12, 2)
+}
+
+func TestParse_Error(t *testing.T) {
 	cases := []struct {
-		name string
-		want int
+		in        string
+		line, col int
 	}{
-		{"testdata/small.txt", 29},
-		{"testdata/input.txt", 492},
+		{"Sabqponm\nabcryxxl\naccsz?xk\n", 3, 6},
+		{"Sabqponm\nabcryxxl\naccs\n", 3, 0},
 	}
 
 	for _, tc := range cases {
-		t.Run(tc.name, func(t *testing.T) {
-			f, err := os.Open(tc.name)
-			if err != nil {
-				t.Fatal(err)
-			}
-			defer f.Close()
+		_, err := Parse(strings.NewReader(tc.in))
 
-			got, err := Part2(f)
-			if err != nil {
-				t.Errorf("error: %v", err)
-			}
-			if got != tc.want {
-				t.Errorf("%v, want %v", got, tc.want)
-			}
-		})
+		var pe *aoc22.ParseError
+		if !errors.As(err, &pe) {
+			t.Errorf("Parse(%q) error = %v, want a ParseError", tc.in, err)
+			continue
+		}
+		if pe.Line != tc.line || pe.Column != tc.col {
+			t.Errorf("Parse(%q) error at %d:%d, want %d:%d", tc.in, pe.Line, pe.Column, tc.line, tc.col)
+		}
+	}
+}
+
+func BenchmarkPart1(b *testing.B) {
+	data := aoc22.ReadTestFile(b, "testdata/input.txt")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Part1(context.Background(), bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPart2(b *testing.B) {
+	data := aoc22.ReadTestFile(b, "testdata/input.txt")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Part2(context.Background(), bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
 	}
 }
@@ -2,12 +2,15 @@ package day13
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"reflect"
 	"sort"
+
+	"github.com/clfs/aoc22"
 )
 
 func PacketToString(p []any) string {
@@ -79,20 +82,23 @@ func CompareFloat(a, b float64) int {
 
 // Compare returns -1, 0, or 1 if a < b, a == b, or a > b.
 func Compare(a, b []any) int {
-	log.Printf("cmp(%v, %v) = ?", a, b)
+	return compare(a, b, nil)
+}
 
+// compare is Compare, tracing each step to tr if it isn't nil.
+func compare(a, b []any, tr aoc22.Tracer) int {
 	if len(a) == 0 && len(b) == 0 {
-		log.Printf("cmp(%v, %v) = 0, since both are empty", a, b)
+		traceCompare(tr, a, b, 0, "both are empty")
 		return 0
 	}
 
 	if len(a) != 0 && len(b) == 0 {
-		log.Printf("cmp(%v, %v) = 1, since only b is empty", a, b)
+		traceCompare(tr, a, b, 1, "only b is empty")
 		return 1
 	}
 
 	if len(a) == 0 && len(b) != 0 {
-		log.Printf("cmp(%v, %v) = -1, since only a is empty", a, b)
+		traceCompare(tr, a, b, -1, "only a is empty")
 		return -1
 	}
 
@@ -100,7 +106,7 @@ func Compare(a, b []any) int {
 
 	for i := range a {
 		if i >= len(b) {
-			log.Printf("cmp(%v, %v) = 1, since b is out of items", a, b)
+			traceCompare(tr, a, b, 1, "b is out of items")
 			return 1
 		}
 
@@ -111,74 +117,85 @@ func Compare(a, b []any) int {
 			biT = reflect.TypeOf(bi)
 		)
 
-		log.Printf("inspect elements %v and %v", ai, bi)
-
 		switch {
 		case aiT == typeFloat64 && biT == typeFloat64:
 			n = CompareFloat(ai.(float64), bi.(float64))
 		case aiT == typePacket && biT == typePacket:
-			n = Compare(ai.([]any), bi.([]any))
+			n = compare(ai.([]any), bi.([]any), tr)
 		case aiT == typePacket && biT == typeFloat64:
-			n = Compare(ai.([]any), []any{bi})
+			n = compare(ai.([]any), []any{bi}, tr)
 		case aiT == typeFloat64 && biT == typePacket:
-			n = Compare([]any{ai}, bi.([]any))
+			n = compare([]any{ai}, bi.([]any), tr)
 		}
 
 		if n != 0 {
-			log.Printf("cmp(%v, %v) = %d, since elements were unequal", a, b, n)
+			traceCompare(tr, a, b, n, "elements were unequal")
 			return n
 		}
 	}
 
 	if len(a) < len(b) {
-		log.Printf("cmp(%v, %v) = -1, since a is out of items", a, b)
+		traceCompare(tr, a, b, -1, "a is out of items")
 		return -1
 	}
 
-	log.Printf("cmp(%v, %v) = %d, after inspecting all elements", a, b, n)
+	traceCompare(tr, a, b, n, "all elements were equal")
 	return n
 }
 
-func Part1(r io.Reader) (int, error) {
+// traceCompare traces the result of comparing a and b, and why.
+func traceCompare(tr aoc22.Tracer, a, b []any, result int, reason string) {
+	if tr == nil {
+		return
+	}
+	tr.Trace("packet.compare",
+		slog.String("a", PacketToString(a)),
+		slog.String("b", PacketToString(b)),
+		slog.Int("result", result),
+		slog.String("reason", reason))
+}
+
+func Part1(ctx context.Context, r io.Reader) (aoc22.Answer, error) {
 	packets, err := Parse(r)
 	if err != nil {
-		return 0, err
+		return aoc22.Answer{}, err
 	}
 
+	tr := aoc22.TracerFrom(ctx)
+
 	var good []int
 	for i := 0; i < len(packets); i += 2 {
-		if Compare(packets[i], packets[i+1]) == -1 {
+		if compare(packets[i], packets[i+1], tr) == -1 {
 			good = append(good, i/2+1)
 		}
 	}
 
-	log.Print(good)
-
 	var sum int
 	for _, i := range good {
 		sum += i
 	}
-	return sum, nil
+	return aoc22.Int(sum), nil
 }
 
-func Part2(r io.Reader) (int, error) {
+func Part2(ctx context.Context, r io.Reader) (aoc22.Answer, error) {
 	packets, err := Parse(r)
 	if err != nil {
-		return 0, err
+		return aoc22.Answer{}, err
 	}
 
 	dividers := []string{"[[2]]", "[[6]]"}
 	for _, d := range dividers {
 		p, err := ParsePacket([]byte(d))
 		if err != nil {
-			return 0, err
+			return aoc22.Answer{}, err
 		}
 		packets = append(packets, p)
 	}
 
 	// Sort packets.
+	tr := aoc22.TracerFrom(ctx)
 	sort.Slice(packets, func(i, j int) bool {
-		return Compare(packets[i], packets[j]) == -1
+		return compare(packets[i], packets[j], tr) == -1
 	})
 
 	product := 1
@@ -189,5 +206,13 @@ func Part2(r io.Reader) (int, error) {
 		}
 	}
 
-	return product, nil
+	return aoc22.Int(product), nil
+}
+
+func init() {
+	aoc22.Register(aoc22.Puzzle{
+		Day:   13,
+		Part1: func(ctx context.Context, r io.Reader, _ aoc22.Params) (aoc22.Answer, error) { return Part1(ctx, r) },
+		Part2: func(ctx context.Context, r io.Reader, _ aoc22.Params) (aoc22.Answer, error) { return Part2(ctx, r) },
+	})
 }
@@ -1,8 +1,14 @@
 package day13
 
 import (
-	"os"
+	"bytes"
+	"context"
+	"math/rand"
+	"reflect"
 	"testing"
+	"testing/quick"
+
+	"github.com/clfs/aoc22"
 )
 
 func toPacket(t *testing.T, s string) []any {
@@ -49,31 +55,7 @@ func TestCompare(t *testing.T) {
 }
 
 func TestPart1(t *testing.T) {
-	cases := []struct {
-		name string
-		want int
-	}{
-		{"testdata/small.txt", 13},
-		{"testdata/input.txt", 6478},
-	}
-
-	for _, tc := range cases {
-		t.Run(tc.name, func(t *testing.T) {
-			f, err := os.Open(tc.name)
-			if err != nil {
-				t.Fatalf("failed to open %q: %v", tc.name, err)
-			}
-			defer f.Close()
-
-			got, err := Part1(f)
-			if err != nil {
-				t.Fatalf("failed to run Part1: %v", err)
-			}
-			if got != tc.want {
-				t.Errorf("Part1() = %d, want %d", got, tc.want)
-			}
-		})
-	}
+	aoc22.CheckAnswers(t, 13, 1)
 }
 
 func FuzzCompare(f *testing.F) {
@@ -106,30 +88,76 @@ func FuzzCompare(f *testing.F) {
 	})
 }
 
+// randPacket is a random packet for property tests. Small numbers and short,
+// shallow lists make equal prefixes, and so deeper comparisons, common.
+type randPacket []any
+
+func (randPacket) Generate(rng *rand.Rand, size int) reflect.Value {
+	return reflect.ValueOf(randPacket(randList(rng, 3)))
+}
+
+func (p randPacket) String() string {
+	return PacketToString(p)
+}
+
+func randList(rng *rand.Rand, depth int) []any {
+	l := make([]any, rng.Intn(4))
+	for i := range l {
+		if depth > 0 && rng.Intn(3) == 0 {
+			l[i] = randList(rng, depth-1)
+		} else {
+			l[i] = float64(rng.Intn(4))
+		}
+	}
+	return l
+}
+
+func TestCompare_Antisymmetric(t *testing.T) {
+	f := func(a, b randPacket) bool {
+		n := Compare(a, b)
+		return n >= -1 && n <= 1 && Compare(b, a) == -n
+	}
+	if err := quick.Check(f, &quick.Config{MaxCount: 10000}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCompare_Transitive(t *testing.T) {
+	f := func(a, b, c randPacket) bool {
+		ab, bc, ac := Compare(a, b), Compare(b, c), Compare(a, c)
+		if ab > 0 || bc > 0 {
+			return true
+		}
+		// a <= b <= c, so a <= c, and a == c only if all three are equal.
+		return ac < 0 || (ac == 0 && ab == 0 && bc == 0)
+	}
+	if err := quick.Check(f, &quick.Config{MaxCount: 10000}); err != nil {
+		t.Error(err)
+	}
+}
+
 func TestPart2(t *testing.T) {
-	cases := []struct {
-		name string
-		want int
-	}{
-		{"testdata/small.txt", 140},
-		{"testdata/input.txt", 21922},
+	aoc22.CheckAnswers(t, 13, 2)
+}
+
+func BenchmarkPart1(b *testing.B) {
+	data := aoc22.ReadTestFile(b, "testdata/input.txt")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Part1(context.Background(), bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
 	}
+}
 
-	for _, tc := range cases {
-		t.Run(tc.name, func(t *testing.T) {
-			f, err := os.Open(tc.name)
-			if err != nil {
-				t.Fatalf("failed to open %q: %v", tc.name, err)
-			}
-			defer f.Close()
-
-			got, err := Part2(f)
-			if err != nil {
-				t.Fatalf("failed to run Part2: %v", err)
-			}
-			if got != tc.want {
-				t.Errorf("Part2() = %d, want %d", got, tc.want)
-			}
-		})
+func BenchmarkPart2(b *testing.B) {
+	data := aoc22.ReadTestFile(b, "testdata/input.txt")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Part2(context.Background(), bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
 	}
 }
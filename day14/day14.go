@@ -2,16 +2,25 @@ package day14
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"io"
-	"log"
-	"strings"
+	"log/slog"
 
 	"github.com/clfs/aoc22"
+	"github.com/clfs/aoc22/anim"
+	"github.com/clfs/aoc22/geom"
+	"github.com/clfs/aoc22/grid"
 )
 
 type Cave struct {
-	tiles [][]int
+	tiles *grid.Grid[int]
+
+	Tracer aoc22.Tracer // If set, receives an event for every move of every grain.
+
+	// If set, gets a frame for every grain that comes to rest. Set it with
+	// Animate.
+	Anim *anim.Recorder
 }
 
 const (
@@ -23,15 +32,22 @@ const (
 
 func NewCave(r io.Reader) (*Cave, error) {
 	c := &Cave{
-		tiles: make([][]int, CaveHeight),
-	}
-	for i := range c.tiles {
-		c.tiles[i] = make([]int, CaveWidth)
+		tiles: grid.New[int](CaveWidth, CaveHeight),
 	}
 
 	s := bufio.NewScanner(r)
-	for s.Scan() {
-		nums := aoc22.ReadInts(s.Text())
+	for line := 1; s.Scan(); line++ {
+		nums, err := aoc22.ReadInts(s.Text())
+		if err != nil {
+			return nil, aoc22.NewParseError(14, line, s.Text(), err)
+		}
+		for i := 0; i+1 < len(nums); i += 2 {
+			if x, y := nums[i], nums[i+1]; !inCave(x, y) {
+				err := fmt.Errorf("rock at %d,%d is outside the cave, which spans x from %d to %d and y from 0 to %d",
+					x, y, -CaveWidthOffset, CaveWidth-CaveWidthOffset-1, maxRockY)
+				return nil, aoc22.NewParseError(14, line, s.Text(), err)
+			}
+		}
 		for i := 0; i < len(nums)-3; i += 2 {
 			x0, y0, x1, y1 := nums[i], nums[i+1], nums[i+2], nums[i+3]
 
@@ -40,30 +56,40 @@ func NewCave(r io.Reader) (*Cave, error) {
 					y0, y1 = y1, y0
 				}
 				for y := y0; y <= y1; y++ {
-					c.Set(NewPoint(x0, y), Rock) // vertical line
+					c.Set(geom.Pt(x0, y), Rock) // vertical line
 				}
 			} else {
 				if x1 < x0 {
 					x0, x1 = x1, x0
 				}
 				for x := x0; x <= x1; x++ {
-					c.Set(NewPoint(x, y0), Rock) // horizontal line
+					c.Set(geom.Pt(x, y0), Rock) // horizontal line
 				}
 			}
 		}
 	}
 
-	c.Set(NewPoint(LeakX, 0), Leak)
+	c.Set(geom.Pt(LeakX, 0), Leak)
 
 	return c, s.Err()
 }
 
-func (c *Cave) Set(p Point, t int) {
-	c.tiles[p.Y][p.X+CaveWidthOffset] = t
+// maxRockY is the lowest rock can be while leaving room for the floor that
+// AddFloor puts two rows below it.
+const maxRockY = CaveHeight - 3
+
+// inCave reports whether rock at x, y fits in the cave.
+func inCave(x, y int) bool {
+	x += CaveWidthOffset
+	return 0 <= x && x < CaveWidth && 0 <= y && y <= maxRockY
+}
+
+func (c *Cave) Set(p geom.Point, t int) {
+	c.tiles.Set(p.Add(geom.Pt(CaveWidthOffset, 0)), t)
 }
 
-func (c *Cave) SetOffset(p Point, t int) {
-	c.tiles[p.Y][p.X] = t
+func (c *Cave) SetOffset(p geom.Point, t int) {
+	c.tiles.Set(p, t)
 }
 
 const (
@@ -77,16 +103,16 @@ const (
 // It returns the y coordinate of the new floor.
 func (c *Cave) AddFloor() int {
 	var best int
-	for i, row := range c.tiles {
-		for _, tile := range row {
+	for y := 0; y < CaveHeight; y++ {
+		for _, tile := range c.tiles.Row(y) {
 			if tile == Rock {
-				best = i
+				best = y
 			}
 		}
 	}
 
 	for x := 0; x < CaveWidth; x++ {
-		c.SetOffset(NewPoint(x, best+2), Rock)
+		c.SetOffset(geom.Pt(x, best+2), Rock)
 	}
 
 	return best + 2
@@ -94,198 +120,255 @@ func (c *Cave) AddFloor() int {
 
 // NumSand returns the number of Sand tiles in the cave.
 func (c *Cave) NumSand() int {
-	var n int
-	for _, row := range c.tiles {
-		for _, tile := range row {
-			if tile == Sand {
-				n++
-			}
-		}
-	}
-	return n
-}
-
-type Point struct {
-	X, Y int
-}
-
-func (p Point) Down() Point {
-	return Point{p.X, p.Y + 1}
-}
-
-func (p Point) DownLeft() Point {
-	return Point{p.X - 1, p.Y + 1}
-}
-
-func (p Point) DownRight() Point {
-	return Point{p.X + 1, p.Y + 1}
-}
-
-func NewPoint(x, y int) Point {
-	return Point{x, y}
+	return c.tiles.Count(func(tile int) bool { return tile == Sand })
 }
 
 // At returns the tile at the given point. If the point is out of bounds, At
 // returns Air.
-func (c *Cave) At(p Point) int {
+func (c *Cave) At(p geom.Point) int {
 	if !c.InBounds(p) {
 		return Air
 	}
-	return c.tiles[p.Y][p.X+CaveWidthOffset]
+	return c.tiles.At(p.Add(geom.Pt(CaveWidthOffset, 0)))
 }
 
 // AtOffset returns the tile at the given point, but does not offset the x
 // coordinate.
-func (c *Cave) AtOffset(p Point) int {
+func (c *Cave) AtOffset(p geom.Point) int {
 	if !c.InBoundsOffset(p) {
 		return Air
 	}
-	return c.tiles[p.Y][p.X]
+	return c.tiles.At(p)
 }
 
-func (c *Cave) InBounds(p Point) bool {
-	if p.Y < 0 {
-		return false
-	}
-
-	if p.Y >= CaveHeight {
-		return false
-	}
-
-	if p.X+CaveWidthOffset < 0 {
-		return false
-	}
-
-	if p.X+CaveWidthOffset >= CaveWidth {
-		return false
-	}
-
-	return true
+func (c *Cave) InBounds(p geom.Point) bool {
+	return c.tiles.In(p.Add(geom.Pt(CaveWidthOffset, 0)))
 }
 
-func (c *Cave) InBoundsOffset(p Point) bool {
-	if p.Y < 0 {
-		return false
-	}
-
-	if p.Y >= CaveHeight {
-		return false
-	}
-
-	if p.X < 0 {
-		return false
-	}
-
-	if p.X >= CaveWidth {
-		return false
-	}
-
-	return true
+func (c *Cave) InBoundsOffset(p geom.Point) bool {
+	return c.tiles.In(p)
 }
 
 // Next returns the next point that the sand will flow to. If the sand cannot
 // flow any further, the point is unchanged.
-func (c *Cave) Next(p Point) Point {
-	switch {
-	case c.AtOffset(p.Down()) == Air:
-		return p.Down()
-	case c.AtOffset(p.DownLeft()) == Air:
-		return p.DownLeft()
-	case c.AtOffset(p.DownRight()) == Air:
-		return p.DownRight()
-	default:
-		return p
+func (c *Cave) Next(p geom.Point) geom.Point {
+	for _, d := range []geom.Point{geom.Down, geom.DownLeft, geom.DownRight} {
+		if c.AtOffset(p.Add(d)) == Air {
+			return p.Add(d)
+		}
 	}
+	return p
 }
 
 func (c *Cave) Tick() (ok bool) {
 	// log.Print("called tick")
 
-	curr := NewPoint(LeakX+CaveWidthOffset, 0)
-	log.Printf("curr: %v", curr)
-	log.Printf("curr tile: %v", c.AtOffset(curr))
+	curr := geom.Pt(LeakX+CaveWidthOffset, 0)
 
 	for {
 		next := c.Next(curr)
-		log.Printf("next: %v, next tile: %v", next, c.AtOffset(next))
 
 		// If the next tile is the leak itself, we're plugged up. Return false.
 		if tile := c.AtOffset(next); tile == Sand {
+			c.trace("sand.plugged", next)
 			return false
 		}
 
 		if next == curr {
-			log.Print("next == curr, so setting curr to sand, then return true")
+			c.trace("sand.rest", curr)
 			c.SetOffset(curr, Sand)
+			if c.Anim != nil {
+				c.Anim.Set(curr.Sub(geom.Pt(CaveWidthOffset, 0)), anim.Yellow)
+				c.Anim.Frame()
+			}
 			return true
 		}
 
-		log.Print("next != curr")
-
 		if !c.InBoundsOffset(next) {
-			log.Print("next out of bounds, returning false")
+			c.trace("sand.abyss", curr)
 			return false
 		}
 
-		log.Print("setting curr to next")
+		c.trace("sand.move", next)
 		curr = next
 	}
 }
 
+// Animate makes r record the cave from now on, starting with a frame of the
+// rock.
+//
+// Sand spreads out by at most one column per row, so only the rock within
+// that many columns of the leak is drawn. Otherwise the floor added for part 2
+// would stretch the picture across the whole cave.
+func (c *Cave) Animate(r *anim.Recorder) {
+	c.Anim = r
+	depth := c.depth()
+
+	leak := geom.Pt(LeakX, 0)
+	r.Set(leak, anim.Red)
+	for _, p := range c.tiles.Points() {
+		p := p.Sub(geom.Pt(CaveWidthOffset, 0))
+		if c.At(p) == Rock && abs(p.X-LeakX) <= depth {
+			r.Set(p, anim.Gray)
+		}
+	}
+	r.Frame()
+}
+
+// depth returns the y coordinate of the lowest rock.
+func (c *Cave) depth() int {
+	var depth int
+	for _, p := range c.tiles.Points() {
+		if c.tiles.At(p) == Rock {
+			depth = max(depth, p.Y)
+		}
+	}
+	return depth
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// trace traces an event for a grain of sand at p, which is offset like in
+// AtOffset. The event has the puzzle's coordinates.
+func (c *Cave) trace(event string, p geom.Point) {
+	if c.Tracer == nil {
+		return
+	}
+	c.Tracer.Trace(event, slog.Int("x", p.X-CaveWidthOffset), slog.Int("y", p.Y))
+}
+
 // TickUntilStable returns the number of sand tiles after sand starts flowing
-// into the abyss below.
-func (c *Cave) TickUntilStable() int {
-	var n int
-
-	log.Printf("==== %d ====", n)
-	log.Print(c.Debug(494+CaveWidthOffset-5, 0, 503+CaveWidthOffset+5, 11))
-	for ; c.Tick(); n++ {
-		log.Printf("==== %d ====", n)
-		log.Print(c.Debug(494+CaveWidthOffset-5, 0, 503+CaveWidthOffset+5, 11))
+// into the abyss below. It returns ctx.Err() if ctx is done first.
+func (c *Cave) TickUntilStable(ctx context.Context) (int, error) {
+	for c.Tick() {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
 	}
-	return c.NumSand()
+	return c.NumSand(), nil
 }
 
-func Part1(r io.Reader) (int, error) {
+func Part1(ctx context.Context, r io.Reader) (aoc22.Answer, error) {
 	c, err := NewCave(r)
 	if err != nil {
-		return 0, err
+		return aoc22.Answer{}, err
+	}
+	c.Tracer = aoc22.TracerFrom(ctx)
+	if r := anim.RecorderFrom(ctx); r != nil {
+		c.Animate(r)
 	}
-	return c.TickUntilStable(), nil
+	n, err := c.TickUntilStable(ctx)
+	if err != nil {
+		return aoc22.Answer{}, err
+	}
+	return aoc22.Int(n), nil
 }
 
-func Part2(r io.Reader) (int, error) {
+func Part2(ctx context.Context, r io.Reader) (aoc22.Answer, error) {
 	c, err := NewCave(r)
 	if err != nil {
-		return 0, err
+		return aoc22.Answer{}, err
+	}
+	c.Tracer = aoc22.TracerFrom(ctx)
+	y := c.AddFloor()
+	if c.Tracer != nil {
+		c.Tracer.Trace("floor.add", slog.Int("y", y))
+	}
+	if r := anim.RecorderFrom(ctx); r != nil {
+		c.Animate(r)
+	}
+	n, err := c.TickUntilStable(ctx)
+	if err != nil {
+		return aoc22.Answer{}, err
 	}
-	n := c.AddFloor()
-	log.Printf("added floor on y=%d", n)
-	return c.TickUntilStable(), nil
+	return aoc22.Int(n), nil
 }
 
+// Debug draws the tiles between (x0, y0) and (x1, y1) inclusive. The x
+// coordinates are offset, like in AtOffset.
 func (c Cave) Debug(x0, y0, x1, y1 int) string {
-	var b strings.Builder
-	for r, row := range c.tiles[y0 : y1+1] {
-		for c, tile := range row[x0 : x1+1] {
-			switch tile {
-			case Air:
-				b.WriteRune('.')
-			case Rock:
-				b.WriteRune('#')
-			case Sand:
-				b.WriteRune('o')
-			case Leak:
-				b.WriteRune('+')
-			default:
-				panic(fmt.Sprintf("unknown tile %d at row %d, col %d", tile, r, c))
-			}
+	window := c.tiles.Window(geom.Pt(x0, y0), geom.Pt(x1, y1))
+	return window.Render(func(tile int) rune {
+		switch tile {
+		case Air:
+			return '.'
+		case Rock:
+			return '#'
+		case Sand:
+			return 'o'
+		case Leak:
+			return '+'
+		default:
+			panic(fmt.Sprintf("unknown tile %d", tile))
+		}
+	})
+}
+
+// Simulate starts a simulation of the sand, one grain at a time. Part 2 adds
+// the floor first.
+func Simulate(part int, r io.Reader) (aoc22.Simulation, error) {
+	c, err := NewCave(r)
+	if err != nil {
+		return nil, err
+	}
+	if part == 2 {
+		c.AddFloor()
+	}
+
+	// Show the rock, plus the columns part 2's sand can spread to, and a
+	// column either side for sand falling into the abyss.
+	depth := c.depth()
+	bounds := geom.Rect{Min: geom.Pt(LeakX, 0), Max: geom.Pt(LeakX, depth)}
+	for _, p := range c.tiles.Points() {
+		p := p.Sub(geom.Pt(CaveWidthOffset, 0))
+		if c.At(p) == Rock && abs(p.X-LeakX) <= depth {
+			bounds = bounds.Include(p)
 		}
-		b.WriteRune('\n')
 	}
-	return b.String()
+	if part == 2 {
+		bounds = bounds.Include(geom.Pt(LeakX-depth, 0))
+		bounds = bounds.Include(geom.Pt(LeakX+depth, 0))
+	}
+	bounds.Min.X--
+	bounds.Max.X++
+
+	return &simulation{cave: c, bounds: bounds}, nil
+}
+
+type simulation struct {
+	cave   *Cave
+	bounds geom.Rect
+	sand   int
+	done   bool
+}
+
+func (s *simulation) Step() bool {
+	if s.done || !s.cave.Tick() {
+		s.done = true
+		return false
+	}
+	s.sand++
+	return true
+}
+
+func (s *simulation) Render() string {
+	b := s.bounds
+	window := s.cave.Debug(b.Min.X+CaveWidthOffset, b.Min.Y, b.Max.X+CaveWidthOffset, b.Max.Y)
+	return fmt.Sprintf("%d grains of sand at rest\n\n%s", s.sand, window)
 }
 
 func init() {
-	log.SetFlags(0)
+	aoc22.Register(aoc22.Puzzle{
+		Day:   14,
+		Part1: func(ctx context.Context, r io.Reader, _ aoc22.Params) (aoc22.Answer, error) { return Part1(ctx, r) },
+		Part2: func(ctx context.Context, r io.Reader, _ aoc22.Params) (aoc22.Answer, error) { return Part2(ctx, r) },
+		Simulate: func(part int, r io.Reader, _ aoc22.Params) (aoc22.Simulation, error) {
+			return Simulate(part, r)
+		},
+	})
 }
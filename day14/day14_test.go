@@ -1,65 +1,96 @@
 package day14
 
 import (
-	"io"
-	"log"
-	"os"
+	"bytes"
+	"context"
+	"errors"
+	"strings"
 	"testing"
+
+	"github.com/clfs/aoc22"
 )
 
 func TestPart1(t *testing.T) {
+	aoc22.CheckAnswers(t, 14, 1)
+}
+
+func TestNewCave_OutOfBounds(t *testing.T) {
 	cases := []struct {
 		name string
-		want int
+		in   string
 	}{
-		{"testdata/small.txt", 24},
-		{"testdata/input.txt", 1078},
+		{"too deep", "498,4 -> 498,300"},
+		{"no room for the floor", "498,4 -> 498,198"},
+		{"negative y", "498,-1 -> 498,4"},
+		{"too far left", "-1001,4 -> 498,4"},
+		{"too far right", "498,4 -> 1000,4"},
 	}
-
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
-			f, err := os.Open(tc.name)
-			if err != nil {
-				t.Fatal(err)
-			}
-			defer f.Close()
+			_, err := NewCave(strings.NewReader("503,4 -> 502,4\n" + tc.in + "\n"))
 
-			got, err := Part1(f)
-			if err != nil {
-				t.Errorf("error: %v", err)
+			var pe *aoc22.ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("NewCave() error = %v, want a ParseError", err)
 			}
-			if got != tc.want {
-				t.Errorf("got %d, want %d", got, tc.want)
+			if pe.Day != 14 || pe.Line != 2 {
+				t.Errorf("NewCave() error = %v, want day 14 on line 2", pe)
 			}
 		})
 	}
 }
 
-func TestPart2(t *testing.T) {
-	cases := []struct {
-		name string
-		want int
-	}{
-		{"testdata/small.txt", 93},
-		{"testdata/input.txt", 30157},
+func TestPart2_DeepestRock(t *testing.T) {
+	// The lowest rock that leaves room for the floor.
+	in := "498,4 -> 498,197\n"
+	if _, err := Part2(context.Background(), strings.NewReader(in)); err != nil {
+		t.Fatal(err)
 	}
-	log.SetOutput(io.Discard)
+}
 
-	for _, tc := range cases {
-		t.Run(tc.name, func(t *testing.T) {
-			f, err := os.Open(tc.name)
-			if err != nil {
-				t.Fatal(err)
-			}
-			defer f.Close()
+func TestPart1_Trace(t *testing.T) {
+	var r aoc22.Recorder
+	ctx := aoc22.WithTracer(context.Background(), &r)
+	if _, err := Part1(ctx, bytes.NewReader(aoc22.ReadTestFile(t, "testdata/small.txt"))); err != nil {
+		t.Fatal(err)
+	}
 
-			got, err := Part2(f)
-			if err != nil {
-				t.Errorf("error: %v", err)
-			}
-			if got != tc.want {
-				t.Errorf("got %d, want %d", got, tc.want)
-			}
-		})
+	rests := r.Events("sand.rest")
+	if len(rests) != 24 {
+		t.Fatalf("got %d sand.rest events, want 24", len(rests))
+	}
+	x, _ := rests[0].Value("x")
+	y, _ := rests[0].Value("y")
+	if x.Int64() != 500 || y.Int64() != 8 {
+		t.Errorf("first grain rests at (%v, %v), want (500, 8)", x, y)
+	}
+	if n := len(r.Events("sand.abyss")); n != 1 {
+		t.Errorf("got %d sand.abyss events, want 1", n)
+	}
+}
+
+func TestPart2(t *testing.T) {
+	aoc22.CheckAnswers(t, 14, 2)
+}
+
+func BenchmarkPart1(b *testing.B) {
+	data := aoc22.ReadTestFile(b, "testdata/input.txt")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Part1(context.Background(), bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPart2(b *testing.B) {
+	data := aoc22.ReadTestFile(b, "testdata/input.txt")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Part2(context.Background(), bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
 	}
 }
@@ -2,11 +2,18 @@ package day15
 
 import (
 	"bufio"
+	"cmp"
+	"context"
 	"fmt"
 	"io"
-	"log"
-
-	"golang.org/x/exp/slices"
+	"math/bits"
+	"math/rand"
+	"regexp"
+	"slices"
+
+	"github.com/clfs/aoc22"
+	"github.com/clfs/aoc22/geom"
+	"github.com/clfs/aoc22/interval"
 )
 
 func abs(x int) int {
@@ -16,34 +23,35 @@ func abs(x int) int {
 	return x
 }
 
-type Point struct {
-	X, Y int
-}
-
 type Sensor struct {
-	Location, NearestBeacon Point
+	Location, NearestBeacon geom.Point
 }
 
+var sensorRegexp = regexp.MustCompile(`^Sensor at x=(?P<sx>-?\d+), y=(?P<sy>-?\d+): closest beacon is at x=(?P<bx>-?\d+), y=(?P<by>-?\d+)$`)
+
 func (s *Sensor) UnmarshalText(text []byte) error {
-	_, err := fmt.Sscanf(
-		string(text),
-		"Sensor at x=%d, y=%d: closest beacon is at x=%d, y=%d",
-		&s.Location.X,
-		&s.Location.Y,
-		&s.NearestBeacon.X,
-		&s.NearestBeacon.Y,
-	)
-	return err
+	var v struct {
+		SX int `aoc:"sx"`
+		SY int `aoc:"sy"`
+		BX int `aoc:"bx"`
+		BY int `aoc:"by"`
+	}
+	if err := aoc22.Decode(sensorRegexp, string(text), &v); err != nil {
+		return err
+	}
+	s.Location = geom.Pt(v.SX, v.SY)
+	s.NearestBeacon = geom.Pt(v.BX, v.BY)
+	return nil
 }
 
 func Parse(r io.Reader) ([]Sensor, error) {
 	var sensors []Sensor
 
 	s := bufio.NewScanner(r)
-	for s.Scan() {
+	for line := 1; s.Scan(); line++ {
 		var sensor Sensor
 		if err := sensor.UnmarshalText(s.Bytes()); err != nil {
-			return nil, err
+			return nil, aoc22.NewParseError(15, line, s.Text(), err)
 		}
 		sensors = append(sensors, sensor)
 	}
@@ -53,7 +61,7 @@ func Parse(r io.Reader) ([]Sensor, error) {
 
 // Radius returns the distance from the sensor to the nearest beacon.
 func (s *Sensor) Radius() int {
-	return abs(s.Location.X-s.NearestBeacon.X) + abs(s.Location.Y-s.NearestBeacon.Y)
+	return s.Location.Manhattan(s.NearestBeacon)
 }
 
 // Intersect returns the range of x values in the intersection of the sensor's
@@ -63,39 +71,13 @@ func (s *Sensor) Intersect(y int) (Range, bool) {
 	if delta < 0 {
 		return Range{}, false
 	}
-	return Range{s.Location.X - delta, s.Location.X + delta}, true
-}
-
-// IntersectSegment returns the range of x values in the intersection of the
-// sensor's area and the segment from (0, y) to (x, y).
-func (s *Sensor) IntersectSegment(x, y int) (Range, bool) {
-	delta := s.Radius() - abs(s.Location.Y-y)
-	if delta < 0 {
-		return Range{}, false
-	}
-	return Range{
-		max(0, s.Location.X-delta),
-		min(x, s.Location.X+delta),
-	}, true
+	return Range{Low: s.Location.X - delta, High: s.Location.X + delta}, true
 }
 
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
-}
-
-// Range is an inclusive range.
-type Range struct {
-	Low, High int
-}
-
-// Len returns the number of integers in the inclusive range.
-func (r Range) Len() int {
-	return r.High - r.Low + 1
-}
+// Range is an inclusive range of x values.
+type Range = interval.Range
 
+// LenUnion returns the number of integers in rs, which must not overlap.
 func LenUnion(rs []Range) int {
 	var res int
 	for _, r := range rs {
@@ -104,9 +86,10 @@ func LenUnion(rs []Range) int {
 	return res
 }
 
+// In reports whether x is in any of the ranges.
 func In(rs []Range, x int) bool {
 	for _, r := range rs {
-		if r.Low <= x && x <= r.High {
+		if r.Contains(x) {
 			return true
 		}
 	}
@@ -115,140 +98,200 @@ func In(rs []Range, x int) bool {
 
 // Union returns the non-overlapping, sorted union of the given ranges.
 // If rs is empty, Union returns nil.
-// If rs is not sorted, Union modifies the order of rs.
 func Union(rs []Range) []Range {
-	if len(rs) == 0 {
-		return nil
-	}
-
-	// First, sort the ranges by low.
-	slices.SortFunc(rs, func(a, b Range) bool { return a.Low < b.Low })
-
-	// Then, merge them.
-	union := []Range{rs[0]}
-	for _, r := range rs[1:] {
-		last := &union[len(union)-1]
-		if r.Low <= last.High+1 {
-			last.High = max(r.High, last.High)
-		} else {
-			union = append(union, r)
-		}
-	}
-
-	return union
-}
-
-func max(a, b int) int {
-	if a > b {
-		return a
-	}
-	return b
+	return interval.Of(rs...).Ranges()
 }
 
-func (r Range) String() string {
-	return fmt.Sprintf("[%d,%d]", r.Low, r.High)
-}
-
-// NImpossible accepts a horizontal line at y, and returns the number of points
-// on the line that cannot contain a beacon.
-func NImpossible(sensors []Sensor, y int) int {
+// Coverage returns the x values on the horizontal line at y that are within
+// range of at least one sensor.
+func Coverage(sensors []Sensor, y int) *interval.Set {
 	var ranges []Range
-
 	for _, s := range sensors {
 		if r, ok := s.Intersect(y); ok {
 			ranges = append(ranges, r)
 		}
 	}
+	return interval.Of(ranges...)
+}
 
-	union := Union(ranges)
+// NImpossible accepts a horizontal line at y, and returns the number of points
+// on the line that cannot contain a beacon.
+func NImpossible(sensors []Sensor, y int) int {
+	covered := Coverage(sensors, y)
 
-	takenX := make(map[int]bool)
+	// Known beacons are on the line, but they're obviously possible.
 	for _, s := range sensors {
 		if s.NearestBeacon.Y == y {
-			takenX[s.NearestBeacon.X] = true
+			covered.Remove(Range{Low: s.NearestBeacon.X, High: s.NearestBeacon.X})
 		}
 	}
 
-	return LenUnion(union) - len(takenX)
+	return covered.Len()
 }
 
 // NImpossibleSegment accepts a horizontal line segment between (0,y) and (x,y)
 // inclusive, and returns the number of points on the segment that cannot
 // contain a beacon.
 func NImpossibleSegment(sensors []Sensor, x, y int) int {
-	var ranges []Range
-
-	for _, s := range sensors {
-		if r, ok := s.IntersectSegment(x, y); ok {
-			ranges = append(ranges, r)
-		}
-	}
-
-	union := Union(ranges)
-
-	return LenUnion(union)
+	return Coverage(sensors, y).Intersect(interval.Of(Range{Low: 0, High: x})).Len()
 }
 
-func Part1(r io.Reader, y int) (int, error) {
+func Part1(ctx context.Context, r io.Reader, y int) (aoc22.Answer, error) {
 	sensors, err := Parse(r)
 	if err != nil {
-		return 0, err
+		return aoc22.Answer{}, err
 	}
 
-	return NImpossible(sensors, y), nil
+	return aoc22.Int(NImpossible(sensors, y)), nil
 }
 
-func Part2(r io.Reader, bound int) (int, error) {
+func Part2(ctx context.Context, r io.Reader, bound int) (aoc22.Answer, error) {
 	sensors, err := Parse(r)
 	if err != nil {
-		return 0, err
+		return aoc22.Answer{}, err
 	}
 
-	p, err := FindDistressBeacon(sensors, bound)
+	p, err := FindDistressBeacon(ctx, sensors, bound)
 	if err != nil {
-		return 0, err
+		return aoc22.Answer{}, err
 	}
 
-	return p.TuningFrequency(), nil
+	return aoc22.Int(TuningFrequency(p)), nil
 }
 
-func (p Point) TuningFrequency() int {
+// TuningFrequency returns the tuning frequency of a distress beacon at p.
+func TuningFrequency(p geom.Point) int {
 	return p.X*4000000 + p.Y
 }
 
 // FindDistressBeacon returns the point of the distress beacon.
 // The beacon is within (0,0)x(bound,bound) inclusive.
-func FindDistressBeacon(sensors []Sensor, bound int) (Point, error) {
+// It returns ctx.Err() if ctx is done before the beacon is found.
+func FindDistressBeacon(ctx context.Context, sensors []Sensor, bound int) (geom.Point, error) {
+	// Millions of rows are checked, so they share one buffer and look for a
+	// gap in place instead of building a Set each.
+	ranges := make([]Range, 0, len(sensors))
+
 	// Backwards, since Eric probably placed it at the bottom
 	for y := bound; y >= 0; y-- {
-
-		nImpossible := NImpossibleSegment(sensors, bound, y)
-
-		if nImpossible > bound {
-			continue
+		// Checking every row would dominate the loop.
+		if (bound-y)%1024 == 0 && ctx.Err() != nil {
+			return geom.Point{}, ctx.Err()
 		}
 
-		for x := 0; x <= bound; x++ {
-			if IsDistressBeacon(sensors, x, y) {
-				log.Printf("distress beacon found at (%d,%d)", x, y)
-				return Point{x, y}, nil
+		ranges = ranges[:0]
+		for _, s := range sensors {
+			if r, ok := s.Intersect(y); ok {
+				ranges = append(ranges, r)
 			}
 		}
+		slices.SortFunc(ranges, func(a, b Range) int { return cmp.Compare(a.Low, b.Low) })
+
+		if x, ok := firstGap(ranges, Range{Low: 0, High: bound}); ok {
+			return geom.Pt(x, y), nil
+		}
 	}
 
-	return Point{}, fmt.Errorf("no distress beacon found")
+	return geom.Point{}, fmt.Errorf("no distress beacon found")
+}
+
+// firstGap returns the smallest x in bounds that isn't in any of the ranges,
+// which must be sorted by their low ends.
+func firstGap(sorted []Range, bounds Range) (int, bool) {
+	x := bounds.Low
+	for _, r := range sorted {
+		if r.Low > x {
+			break
+		}
+		x = max(x, r.High+1)
+	}
+	return x, x <= bounds.High
 }
 
 func IsDistressBeacon(sensors []Sensor, x, y int) bool {
-	p := Point{x, y}
+	p := geom.Pt(x, y)
 	for _, s := range sensors {
-		if Distance(s.Location, p) <= s.Radius() {
+		if s.Location.Manhattan(p) <= s.Radius() {
 			return false
 		}
 	}
 	return true
 }
 
-func Distance(p1, p2 Point) int {
-	return abs(p1.X-p2.X) + abs(p1.Y-p2.Y)
+// Generate writes sensors that leave exactly one cell uncovered between 0 and
+// size in both coordinates, and returns the tuning frequency of that cell as
+// the answer to part 2. The part 1 answer isn't known.
+//
+// Four large sensors, one diagonally out from the distress beacon in each
+// direction, stop just short of it and cover everything else. Smaller sensors,
+// about as many as the bits in size, are scattered around so that the large
+// ones aren't the only ones in range.
+//
+// The output is only a valid input for part 2. The large sensors reach
+// almost the whole area, so the small sensors' beacons are usually closer to
+// one of them than the beacon it lists, which a real input never has. Part 1
+// still runs on it, but its answer means nothing.
+func Generate(w io.Writer, rng *rand.Rand, size int) (aoc22.Generated, error) {
+	if size == 0 {
+		size = 4000000
+	}
+	if size < 2 {
+		return aoc22.Generated{}, fmt.Errorf("invalid size %d", size)
+	}
+
+	beacon := geom.Pt(rng.Intn(size+1), rng.Intn(size+1))
+
+	var sensors []Sensor
+	t := size + 1
+	for _, dir := range []geom.Point{geom.Pt(1, 1), geom.Pt(1, -1), geom.Pt(-1, 1), geom.Pt(-1, -1)} {
+		loc := beacon.Add(dir.Mul(t))
+		sensors = append(sensors, Sensor{loc, loc.Add(geom.Pt(dir.X*(2*t-1), 0))})
+	}
+	for n := bits.Len(uint(size)); n > 0; {
+		loc := geom.Pt(rng.Intn(size+1), rng.Intn(size+1))
+		d := loc.Manhattan(beacon)
+		if d < 2 {
+			continue
+		}
+		r := 1 + rng.Intn(d-1)
+		dx := rng.Intn(2*r+1) - r
+		dy := r - abs(dx)
+		if rng.Intn(2) == 0 {
+			dy = -dy
+		}
+		sensors = append(sensors, Sensor{loc, loc.Add(geom.Pt(dx, dy))})
+		n--
+	}
+	rng.Shuffle(len(sensors), func(i, j int) { sensors[i], sensors[j] = sensors[j], sensors[i] })
+
+	bw := bufio.NewWriter(w)
+	for _, s := range sensors {
+		fmt.Fprintf(bw, "Sensor at x=%d, y=%d: closest beacon is at x=%d, y=%d\n",
+			s.Location.X, s.Location.Y, s.NearestBeacon.X, s.NearestBeacon.Y)
+	}
+	if err := bw.Flush(); err != nil {
+		return aoc22.Generated{}, err
+	}
+
+	return aoc22.Generated{
+		Params:  aoc22.Params{"y": size / 2, "bound": size},
+		Answers: [2]aoc22.Answer{1: aoc22.Int(TuningFrequency(beacon))},
+	}, nil
+}
+
+func init() {
+	aoc22.Register(aoc22.Puzzle{
+		Day: 15,
+		Part1: func(ctx context.Context, r io.Reader, params aoc22.Params) (aoc22.Answer, error) {
+			return Part1(ctx, r, params["y"])
+		},
+		Part2: func(ctx context.Context, r io.Reader, params aoc22.Params) (aoc22.Answer, error) {
+			return Part2(ctx, r, params["bound"])
+		},
+		Generate: Generate,
+		Params: []aoc22.Param{
+			{Name: "y", Usage: "row to scan for impossible beacon positions (part 1)", Default: 2000000},
+			{Name: "bound", Usage: "largest coordinate of the distress beacon (part 2)", Default: 4000000},
+		},
+	})
 }
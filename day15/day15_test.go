@@ -1,10 +1,18 @@
 package day15
 
 import (
-	"os"
+	"bytes"
+	"context"
+	"errors"
+	"math/rand"
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
+	"testing/quick"
 
-	"golang.org/x/exp/slices"
+	"github.com/clfs/aoc22"
+	"github.com/clfs/aoc22/geom"
 )
 
 func TestSensor_Radius(t *testing.T) {
@@ -12,8 +20,8 @@ func TestSensor_Radius(t *testing.T) {
 		s    Sensor
 		want int
 	}{
-		{Sensor{Point{2, 2}, Point{3, 3}}, 2},
-		{Sensor{Point{-2, 0}, Point{0, -1}}, 3},
+		{Sensor{geom.Pt(2, 2), geom.Pt(3, 3)}, 2},
+		{Sensor{geom.Pt(-2, 0), geom.Pt(0, -1)}, 3},
 	}
 
 	for _, tc := range cases {
@@ -23,19 +31,42 @@ func TestSensor_Radius(t *testing.T) {
 	}
 }
 
+func TestParse_Error(t *testing.T) {
+	cases := []struct {
+		in        string
+		line, col int
+	}{
+		{"Sensor at x=2, y=18: closest beacon is at x=-2, y=15\nSensor at x=9 y=16\n", 2, 0},
+		{"Sensor at x=99999999999999999999, y=18: closest beacon is at x=-2, y=15\n", 1, 13},
+	}
+
+	for _, tc := range cases {
+		_, err := Parse(strings.NewReader(tc.in))
+
+		var pe *aoc22.ParseError
+		if !errors.As(err, &pe) {
+			t.Errorf("Parse(%q) error = %v, want a ParseError", tc.in, err)
+			continue
+		}
+		if pe.Day != 15 || pe.Line != tc.line || pe.Column != tc.col {
+			t.Errorf("Parse(%q) error = %v, want day 15 at %d:%d", tc.in, pe, tc.line, tc.col)
+		}
+	}
+}
+
 func TestLenUnion(t *testing.T) {
 	cases := []struct {
 		in   []Range
 		want int
 	}{
-		{[]Range{{0, 1}, {2, 3}}, 4},
-		{[]Range{{0, 1}, {2, 3}, {4, 5}}, 6},
-		{[]Range{{0, 1}, {0, 2}}, 3},
-		{[]Range{{-1, 0}, {0, 1}}, 3},
-		{[]Range{{-10, 10}, {-10, 10}}, 21},
-		{[]Range{{-10, 10}, {-10, 10}, {-10, 10}}, 21},
+		{[]Range{{Low: 0, High: 1}, {Low: 2, High: 3}}, 4},
+		{[]Range{{Low: 0, High: 1}, {Low: 2, High: 3}, {Low: 4, High: 5}}, 6},
+		{[]Range{{Low: 0, High: 1}, {Low: 0, High: 2}}, 3},
+		{[]Range{{Low: -1, High: 0}, {Low: 0, High: 1}}, 3},
+		{[]Range{{Low: -10, High: 10}, {Low: -10, High: 10}}, 21},
+		{[]Range{{Low: -10, High: 10}, {Low: -10, High: 10}, {Low: -10, High: 10}}, 21},
 		{[]Range{}, 0},
-		{[]Range{{0, 1}, {-1, 2}}, 4},
+		{[]Range{{Low: 0, High: 1}, {Low: -1, High: 2}}, 4},
 	}
 
 	for _, tc := range cases {
@@ -50,12 +81,12 @@ func TestRange_Len(t *testing.T) {
 		r    Range
 		want int
 	}{
-		{Range{0, 1}, 2},
-		{Range{0, 2}, 3},
-		{Range{0, 0}, 1},
-		{Range{-1, 0}, 2},
-		{Range{-1, -1}, 1},
-		{Range{-2, -1}, 2},
+		{Range{Low: 0, High: 1}, 2},
+		{Range{Low: 0, High: 2}, 3},
+		{Range{Low: 0, High: 0}, 1},
+		{Range{Low: -1, High: 0}, 2},
+		{Range{Low: -1, High: -1}, 1},
+		{Range{Low: -2, High: -1}, 2},
 	}
 
 	for _, tc := range cases {
@@ -71,51 +102,98 @@ func TestUnion(t *testing.T) {
 		want []Range
 	}{
 		{
-			[]Range{{0, 1}, {2, 3}},
-			[]Range{{0, 3}},
+			[]Range{{Low: 0, High: 1}, {Low: 2, High: 3}},
+			[]Range{{Low: 0, High: 3}},
 		},
 		{
-			[]Range{{0, 1}, {2, 3}, {4, 5}},
-			[]Range{{0, 5}},
+			[]Range{{Low: 0, High: 1}, {Low: 2, High: 3}, {Low: 4, High: 5}},
+			[]Range{{Low: 0, High: 5}},
 		},
 		{
-			[]Range{{0, 1}, {0, 2}},
-			[]Range{{0, 2}},
+			[]Range{{Low: 0, High: 1}, {Low: 0, High: 2}},
+			[]Range{{Low: 0, High: 2}},
 		},
 		{
-			[]Range{{-1, 0}, {0, 1}},
-			[]Range{{-1, 1}},
+			[]Range{{Low: -1, High: 0}, {Low: 0, High: 1}},
+			[]Range{{Low: -1, High: 1}},
 		},
 		{
-			[]Range{{-10, 10}, {-10, 10}},
-			[]Range{{-10, 10}},
+			[]Range{{Low: -10, High: 10}, {Low: -10, High: 10}},
+			[]Range{{Low: -10, High: 10}},
 		},
 		{
-			[]Range{{-10, 10}, {-10, 10}, {-10, 10}},
-			[]Range{{-10, 10}},
+			[]Range{{Low: -10, High: 10}, {Low: -10, High: 10}, {Low: -10, High: 10}},
+			[]Range{{Low: -10, High: 10}},
 		},
 		{
 			[]Range{},
 			[]Range{},
 		},
 		{
-			[]Range{{0, 1}, {-1, 2}},
-			[]Range{{-1, 2}},
+			[]Range{{Low: 0, High: 1}, {Low: -1, High: 2}},
+			[]Range{{Low: -1, High: 2}},
 		},
 		{
-			[]Range{{0, 1}, {4, 5}},
-			[]Range{{0, 1}, {4, 5}},
+			[]Range{{Low: 0, High: 1}, {Low: 4, High: 5}},
+			[]Range{{Low: 0, High: 1}, {Low: 4, High: 5}},
 		},
 	}
 
 	for _, tc := range cases {
-		in := tc.in // Union modifies its argument if unsorted
-		if got := Union(in); !slices.Equal(got, tc.want) {
+		if got := Union(tc.in); !slices.Equal(got, tc.want) {
 			t.Errorf("Union of %v = %v, want %v", tc.in, got, tc.want)
 		}
 	}
 }
 
+// randRanges is a list of random ranges for property tests. They're short
+// and close together, so they often overlap, and some are empty.
+type randRanges []Range
+
+func (randRanges) Generate(rng *rand.Rand, size int) reflect.Value {
+	rs := make(randRanges, rng.Intn(9))
+	for i := range rs {
+		low := rng.Intn(61) - 30
+		rs[i] = Range{Low: low, High: low - 1 + rng.Intn(15)}
+	}
+	return reflect.ValueOf(rs)
+}
+
+func TestUnion_Properties(t *testing.T) {
+	idempotent := func(rs randRanges) bool {
+		u := Union(rs)
+		return slices.Equal(Union(u), u)
+	}
+	sortedAndDisjoint := func(rs randRanges) bool {
+		u := Union(rs)
+		for i, r := range u {
+			if r.Empty() || (i > 0 && u[i-1].High+1 >= r.Low) {
+				return false
+			}
+		}
+		return true
+	}
+	sameMembers := func(rs randRanges) bool {
+		u := Union(rs)
+		for x := -32; x <= 46; x++ {
+			if In(rs, x) != In(u, x) {
+				return false
+			}
+		}
+		return true
+	}
+
+	for name, f := range map[string]func(randRanges) bool{
+		"idempotent":          idempotent,
+		"sorted and disjoint": sortedAndDisjoint,
+		"same members":        sameMembers,
+	} {
+		if err := quick.Check(f, &quick.Config{MaxCount: 1000}); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
 func TestSensor_Intersect(t *testing.T) {
 	cases := []struct {
 		s      Sensor
@@ -124,15 +202,15 @@ func TestSensor_Intersect(t *testing.T) {
 		wantOk bool
 	}{
 		{
-			Sensor{Point{0, 0}, Point{0, 2}},
+			Sensor{geom.Pt(0, 0), geom.Pt(0, 2)},
 			-1,
-			Range{-1, 1},
+			Range{Low: -1, High: 1},
 			true,
 		},
 		{
-			Sensor{Point{0, 0}, Point{0, 2}},
+			Sensor{geom.Pt(0, 0), geom.Pt(0, 2)},
 			0,
-			Range{-2, 2},
+			Range{Low: -2, High: 2},
 			true,
 		},
 	}
@@ -155,65 +233,88 @@ func TestSensor_Intersect(t *testing.T) {
 }
 
 func TestPart1(t *testing.T) {
-	cases := []struct {
-		name string
-		y    int
-		want int
-	}{
-		{"testdata/small.txt", 10, 26},
-		{"testdata/input.txt", 2000000, 4748135},
-	}
+	aoc22.CheckAnswers(t, 15, 1)
+}
 
-	for _, tc := range cases {
-		t.Run(tc.name, func(t *testing.T) {
-			f, err := os.Open(tc.name)
+func TestPart2(t *testing.T) {
+	aoc22.CheckAnswers(t, 15, 2)
+}
+
+func TestGenerate(t *testing.T) {
+	for _, size := range []int{2, 3, 20, 1000, 100000} {
+		for seed := int64(1); seed <= 5; seed++ {
+			var buf bytes.Buffer
+			g, err := Generate(&buf, rand.New(rand.NewSource(seed)), size)
 			if err != nil {
-				t.Fatal(err)
+				t.Fatalf("size %d, seed %d: %v", size, seed, err)
 			}
-			defer f.Close()
 
-			got, err := Part1(f, tc.y)
+			want, _ := g.Answer(2)
+			got, err := Part2(context.Background(), bytes.NewReader(buf.Bytes()), g.Params["bound"])
 			if err != nil {
-				t.Errorf("error: %v", err)
+				t.Fatalf("size %d, seed %d: %v", size, seed, err)
 			}
-			if got != tc.want {
-				t.Errorf(
-					"Part1(%q, %d) = %d; want %d",
-					tc.name, tc.y, got, tc.want,
-				)
+			if got != want {
+				t.Errorf("size %d, seed %d: got %v, want %v", size, seed, got, want)
 			}
-		})
+		}
 	}
 }
 
-func TestPart2(t *testing.T) {
+func BenchmarkPart1(b *testing.B) {
+	data := aoc22.ReadTestFile(b, "testdata/input.txt")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Part1(context.Background(), bytes.NewReader(data), 2000000); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPart2(b *testing.B) {
+	data := aoc22.ReadTestFile(b, "testdata/input.txt")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Part2(context.Background(), bytes.NewReader(data), 4000000); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestFirstGap(t *testing.T) {
+	bounds := Range{Low: 0, High: 20}
 	cases := []struct {
-		name  string
-		bound int
-		want  int
+		sorted []Range
+		want   int
+		ok     bool
 	}{
-		{"testdata/small.txt", 20, 56000011},
-		{"testdata/input.txt", 4000000, 13743542639657},
+		{nil, 0, true},
+		{[]Range{{Low: -5, High: 20}}, 0, false},
+		{[]Range{{Low: 0, High: 4}, {Low: 6, High: 20}}, 5, true},
+		{[]Range{{Low: -2, High: 10}, {Low: 3, High: 5}, {Low: 11, High: 25}}, 0, false},
+		{[]Range{{Low: 0, High: 10}, {Low: 2, High: 12}}, 13, true},
+		{[]Range{{Low: 1, High: 20}}, 0, true},
 	}
 
 	for _, tc := range cases {
-		t.Run(tc.name, func(t *testing.T) {
-			f, err := os.Open(tc.name)
-			if err != nil {
-				t.Fatal(err)
-			}
-			defer f.Close()
+		got, ok := firstGap(tc.sorted, bounds)
+		if ok != tc.ok || (ok && got != tc.want) {
+			t.Errorf("firstGap(%v) = %d, %t, want %d, %t", tc.sorted, got, ok, tc.want, tc.ok)
+		}
+	}
+}
 
-			got, err := Part2(f, tc.bound)
-			if err != nil {
-				t.Errorf("error: %v", err)
-			}
-			if got != tc.want {
-				t.Errorf(
-					"Part2(%q, %d) = %d; want %d",
-					tc.name, tc.bound, got, tc.want,
-				)
-			}
-		})
+func TestFindDistressBeacon_Canceled(t *testing.T) {
+	sensors, err := Parse(bytes.NewReader(aoc22.ReadTestFile(t, "testdata/input.txt")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := FindDistressBeacon(ctx, sensors, 4000000); err != context.Canceled {
+		t.Errorf("error = %v, want %v", err, context.Canceled)
 	}
 }
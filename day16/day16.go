@@ -2,37 +2,29 @@ package day16
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"math/rand"
 	"regexp"
-	"strconv"
+	"slices"
 	"strings"
 
-	"golang.org/x/exp/slices"
+	"github.com/clfs/aoc22"
 )
 
 type Valve struct {
-	Name    string
-	Rate    int
-	Tunnels []string
+	Name    string   `aoc:"name"`
+	Rate    int      `aoc:"rate"`
+	Tunnels []string `aoc:"tunnels" sep:", "`
 }
 
-var ValveRegexp = regexp.MustCompile(`Valve ([A-Z]{2}) has flow rate=(\d+); (?:tunnels lead|tunnel leads) to (?:valves|valve) ([A-Z]{2}(?:, [A-Z]{2})*)`)
+var ValveRegexp = regexp.MustCompile(`^Valve (?P<name>[A-Z]{2}) has flow rate=(?P<rate>\d+); (?:tunnels lead|tunnel leads) to (?:valves|valve) (?P<tunnels>[A-Z]{2}(?:, [A-Z]{2})*)$`)
 
 func (v *Valve) UnmarshalText(text []byte) error {
-	matches := ValveRegexp.FindStringSubmatch(string(text))
-	if matches == nil {
-		return fmt.Errorf("no match for %q", text)
-	}
-
-	v.Name = matches[1]
-	v.Tunnels = strings.Split(matches[3], ", ")
-
-	var err error
-	v.Rate, err = strconv.Atoi(matches[2])
-	return err
+	return aoc22.Decode(ValveRegexp, string(text), v)
 }
 
 type Volcano struct {
@@ -48,6 +40,8 @@ type Volcano struct {
 
 	// Cached results for Volcano.LenPath.
 	LenPathCache map[string]map[string]int
+
+	Tracer aoc22.Tracer // If set, receives the steps of simulations and searches.
 }
 
 func NewVolcano(vs []Valve, limit int) *Volcano {
@@ -74,44 +68,43 @@ func NewVolcano(vs []Valve, limit int) *Volcano {
 	return volcano
 }
 
+// Rate returns the flow rate of a valve. Unknown valves have no flow.
 func (v *Volcano) Rate(name string) int {
-	rate, ok := v.Rates[name]
-	if !ok {
-		panic("bad name " + name)
-	}
-	return rate
+	return v.Rates[name]
 }
 
-func (v *Volcano) Open(name string) {
+// Open opens a valve. It returns an error if the valve is unknown or already
+// open.
+func (v *Volcano) Open(name string) error {
 	status, ok := v.Status[name]
 	if !ok {
-		panic("bad name " + name)
+		return fmt.Errorf("unknown valve %s", name)
 	}
 	if status {
-		panic(name + " already open")
+		return fmt.Errorf("valve %s already open", name)
 	}
 	v.Status[name] = true
+	return nil
 }
 
-func (v *Volcano) IsOpen(name string) bool {
+// IsOpen reports whether a valve is open. It returns an error if the valve is
+// unknown.
+func (v *Volcano) IsOpen(name string) (bool, error) {
 	status, ok := v.Status[name]
 	if !ok {
-		panic("bad name " + name)
+		return false, fmt.Errorf("unknown valve %s", name)
 	}
-	return status
+	return status, nil
 }
 
-func (v *Volcano) IsClosed(name string) bool {
-	return !v.IsOpen(name)
-}
-
-// NextFrom returns the valves that are next from the given valve.
-func (v *Volcano) NextFrom(name string) []string {
+// NextFrom returns the valves that are next from the given valve. It returns
+// an error if the valve is unknown.
+func (v *Volcano) NextFrom(name string) ([]string, error) {
 	edges, ok := v.Edges[name]
 	if !ok {
-		panic("bad name " + name)
+		return nil, fmt.Errorf("unknown valve %s", name)
 	}
-	return edges
+	return edges, nil
 }
 
 // Path returns the shortest path between from and to.
@@ -130,7 +123,7 @@ func (v *Volcano) Path(from, to string) ([]string, bool) {
 				return path, true
 			}
 
-			for _, next := range v.NextFrom(tail) {
+			for _, next := range v.Edges[tail] {
 				if visited[next] {
 					continue
 				}
@@ -153,7 +146,7 @@ func (v *Volcano) BestMove() (string, bool) {
 	// Get the shortest path to every useful valve.
 	paths := make(map[string][]string)
 	for _, name := range v.Nodes {
-		if v.IsOpen(name) || v.Rate(name) == 0 {
+		if v.Status[name] || v.Rate(name) == 0 {
 			continue // useless
 		}
 		path, ok := v.Path(v.Location, name)
@@ -176,7 +169,7 @@ func (v *Volcano) BestMove() (string, bool) {
 		}
 	}
 
-	log.Printf("- best path with score %d: %v", bestScore, bestPath)
+	v.traceBest("move.best", bestScore, bestPath)
 
 	switch l := len(bestPath); l {
 	case 0:
@@ -203,7 +196,7 @@ func (v *Volcano) Score(path []string) int {
 	tail := path[len(path)-1]
 	tailRate := v.Rate(tail)
 
-	if v.IsOpen(tail) {
+	if v.Status[tail] {
 		return 0
 	}
 
@@ -219,32 +212,37 @@ func (v *Volcano) Score(path []string) int {
 func (v *Volcano) Tick() int {
 	defer func() { v.TimeElapsed++ }()
 
-	log.Printf("==== minute %d ====", v.TimeElapsed)
+	if v.Tracer != nil {
+		v.Tracer.Trace("minute.start", slog.Int("minute", v.TimeElapsed))
+	}
 
 	var (
 		pressure   int
 		openValves []string
 	)
 	for _, name := range v.Nodes {
-		if v.IsOpen(name) {
+		if v.Status[name] {
 			openValves = append(openValves, name)
 			pressure += v.Rate(name)
 		}
 	}
-	if len(openValves) > 0 {
-		log.Printf(
-			"open valve(s) %s released %d pressure",
-			strings.Join(openValves, ", "), pressure,
-		)
+	if len(openValves) > 0 && v.Tracer != nil {
+		v.Tracer.Trace("pressure.release",
+			slog.String("valves", strings.Join(openValves, ",")),
+			slog.Int("pressure", pressure))
 	}
 
 	move, ok := v.BestMove()
 	if ok {
 		if move == v.Location {
-			log.Printf("opening valve %s", move)
-			v.Open(move)
+			if v.Tracer != nil {
+				v.Tracer.Trace("valve.open", slog.String("valve", move))
+			}
+			v.Status[move] = true // BestMove only picks closed valves.
 		} else {
-			log.Printf("moving to valve %s", move)
+			if v.Tracer != nil {
+				v.Tracer.Trace("valve.move", slog.String("valve", move))
+			}
 			v.Location = move
 		}
 	}
@@ -252,6 +250,38 @@ func (v *Volcano) Tick() int {
 	return pressure
 }
 
+// Debug describes the state of the simulation: the time, where you are, and
+// which valves are open.
+func (v *Volcano) Debug() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "minute %d of %d, at valve %s\n", v.TimeElapsed, v.TimeLimit, v.Location)
+
+	var open []string
+	pressure := 0
+	for _, name := range v.Nodes {
+		if v.Status[name] {
+			open = append(open, name)
+			pressure += v.Rate(name)
+		}
+	}
+	fmt.Fprintf(&b, "open valves: %s (%d pressure per minute)\n", strings.Join(open, ", "), pressure)
+
+	for _, name := range v.Nodes {
+		if !v.Status[name] && v.Rate(name) > 0 {
+			fmt.Fprintf(&b, "  closed %s: rate %d, %d minutes away\n", name, v.Rate(name), v.LenPath(v.Location, name))
+		}
+	}
+	return b.String()
+}
+
+// traceBest traces a new best path and its score.
+func (v *Volcano) traceBest(event string, score int, path []string) {
+	if v.Tracer == nil {
+		return
+	}
+	v.Tracer.Trace(event, slog.Int("score", score), slog.String("path", strings.Join(path, ",")))
+}
+
 // Run runs the simulation until the time limit is reached.
 // It returns the total amount of pressure released.
 func (v *Volcano) Run() int {
@@ -262,35 +292,70 @@ func (v *Volcano) Run() int {
 	return sum
 }
 
+// Parse parses a list of valves, one per line. Every tunnel must lead to a
+// valve in the list, and valve AA, where you start, must be in it.
 func Parse(r io.Reader) ([]Valve, error) {
-	var valves []Valve
+	var (
+		valves []Valve
+		lines  []string
+	)
 	s := bufio.NewScanner(r)
 	for s.Scan() {
 		var v Valve
 		if err := v.UnmarshalText(s.Bytes()); err != nil {
-			return nil, err
+			return nil, aoc22.NewParseError(16, len(valves)+1, s.Text(), err)
 		}
 		valves = append(valves, v)
+		lines = append(lines, s.Text())
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool)
+	for _, v := range valves {
+		known[v.Name] = true
+	}
+	if !known["AA"] {
+		return nil, &aoc22.ParseError{Day: 16, Err: errors.New("no valve AA to start from")}
 	}
-	return valves, s.Err()
+	for i, v := range valves {
+		for _, t := range v.Tunnels {
+			if !known[t] {
+				return nil, &aoc22.ParseError{
+					Day:    16,
+					Line:   i + 1,
+					Column: strings.LastIndex(lines[i], t) + 1,
+					Text:   lines[i],
+					Err:    fmt.Errorf("tunnel to unknown valve %s", t),
+				}
+			}
+		}
+	}
+
+	return valves, nil
 }
 
-func Part1(r io.Reader) (int, error) {
+func Part1(ctx context.Context, r io.Reader) (aoc22.Answer, error) {
 	valves, err := Parse(r)
 	if err != nil {
-		return 0, err
+		return aoc22.Answer{}, err
 	}
-	_, score := NewVolcano(valves, 30).Solve2()
-	return score, nil
+	v := NewVolcano(valves, 30)
+	v.Tracer = aoc22.TracerFrom(ctx)
+	_, score, err := v.Solve2(ctx)
+	return aoc22.Int(score), err
 }
 
-func Part2(r io.Reader) (int, error) {
+func Part2(ctx context.Context, r io.Reader) (aoc22.Answer, error) {
 	valves, err := Parse(r)
 	if err != nil {
-		return 0, err
+		return aoc22.Answer{}, err
 	}
-	score := NewVolcano(valves, 26).Solve3()
-	return score, nil
+	v := NewVolcano(valves, 26)
+	v.Tracer = aoc22.TracerFrom(ctx)
+	score, err := v.Solve3(ctx)
+	return aoc22.Int(score), err
 }
 
 // RandSample returns a sample of size n from pop. It alters the order
@@ -303,10 +368,12 @@ func RandSample(pop []string, n int) ([]string, bool) {
 	return pop[:n], true
 }
 
-func (v *Volcano) Solve() int {
+// Solve searches random orders of the closed valves for the best score. It
+// runs until ctx is done, then returns the best score found and ctx.Err().
+func (v *Volcano) Solve(ctx context.Context) (int, error) {
 	var targets []string
 	for _, name := range v.Nodes {
-		if v.IsClosed(name) && v.Rate(name) > 0 {
+		if !v.Status[name] && v.Rate(name) > 0 {
 			targets = append(targets, name)
 		}
 	}
@@ -319,6 +386,10 @@ func (v *Volcano) Solve() int {
 	n := min(len(targets), 9)
 
 	for i := 0; i < 1000000000; i++ {
+		// Checking every shuffle would dominate the loop.
+		if i%1024 == 0 && ctx.Err() != nil {
+			return bestScore, ctx.Err()
+		}
 		rand.Shuffle(len(targets), func(i, j int) { targets[i], targets[j] = targets[j], targets[i] })
 		score := v.Evaluate(targets[:n])
 		if score > bestScore {
@@ -326,11 +397,11 @@ func (v *Volcano) Solve() int {
 			tmp := make([]string, n)
 			copy(tmp, targets[:n])
 			bestSample = tmp
-			log.Printf("score %d with sample %v", bestScore, bestSample)
+			v.traceBest("search.best", bestScore, bestSample)
 		}
 	}
 
-	return bestScore
+	return bestScore, nil
 }
 
 func min(a, b int) int {
@@ -340,6 +411,13 @@ func min(a, b int) int {
 	return b
 }
 
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
 // Evaluate determines how much pressure would be released if you
 // visited each target in order and opened them.
 //
@@ -424,7 +502,7 @@ func (v *Volcano) LenPath(from, to string) int {
 				v.LenPathCache[from][to] = count
 				return count
 			}
-			for _, next := range v.NextFrom(name) {
+			for _, next := range v.Edges[name] {
 				if visited[next] {
 					continue
 				}
@@ -454,10 +532,12 @@ new strategy:
 
 */
 
-func (v *Volcano) Solve2() ([]string, int) {
+// Solve2 returns the best path through the closed valves and its score. If
+// ctx is done first, it returns the best path found so far and ctx.Err().
+func (v *Volcano) Solve2(ctx context.Context) ([]string, int, error) {
 	var targets []string
 	for _, name := range v.Nodes {
-		if v.IsClosed(name) && v.Rate(name) > 0 {
+		if !v.Status[name] && v.Rate(name) > 0 {
 			targets = append(targets, name)
 		}
 	}
@@ -473,6 +553,9 @@ func (v *Volcano) Solve2() ([]string, int) {
 	}
 
 	for len(queue) > 0 {
+		if ctx.Err() != nil {
+			return bestPath, bestScore, ctx.Err()
+		}
 		path := queue[0]
 		queue = queue[1:]
 
@@ -481,7 +564,7 @@ func (v *Volcano) Solve2() ([]string, int) {
 		if score > bestScore {
 			bestScore = score
 			bestPath = path
-			log.Printf("⭐️ best score %d with path %v", bestScore, bestPath)
+			v.traceBest("search.best", bestScore, bestPath)
 		} else if score == 0 {
 			continue
 		}
@@ -504,13 +587,15 @@ func (v *Volcano) Solve2() ([]string, int) {
 		}
 	}
 
-	return bestPath, bestScore
+	return bestPath, bestScore, nil
 }
 
-func (v *Volcano) Solve3() int {
+// Solve3 is like Solve2, but with an elephant opening the valves you don't.
+// It returns the best combined score.
+func (v *Volcano) Solve3(ctx context.Context) (int, error) {
 	var targets []string
 	for _, name := range v.Nodes {
-		if v.IsClosed(name) && v.Rate(name) > 0 {
+		if !v.Status[name] && v.Rate(name) > 0 {
 			targets = append(targets, name)
 		}
 	}
@@ -526,6 +611,9 @@ func (v *Volcano) Solve3() int {
 	}
 
 	for len(queue) > 0 {
+		if ctx.Err() != nil {
+			return bestScore, ctx.Err()
+		}
 		path := queue[0]
 		queue = queue[1:]
 
@@ -536,13 +624,16 @@ func (v *Volcano) Solve3() int {
 
 		// What if there was an elephant?
 
-		_, bonus := v.SolveElephant(path)
+		_, bonus, err := v.SolveElephant(ctx, path)
 		score += bonus
+		if err != nil {
+			return max(score, bestScore), err
+		}
 
 		if score > bestScore {
 			bestScore = score
 			bestPath = path
-			log.Printf("⭐️ best score %d with path %v", bestScore, bestPath)
+			v.traceBest("search.best", bestScore, bestPath)
 		} else if score == 0 {
 			continue
 		}
@@ -565,14 +656,17 @@ func (v *Volcano) Solve3() int {
 		}
 	}
 
-	return bestScore
+	return bestScore, nil
 }
 
-func (v *Volcano) SolveElephant(used []string) ([]string, int) {
+// SolveElephant returns the elephant's best path through the closed valves
+// not in used, and its score. If ctx is done first, it returns the best path
+// found so far and ctx.Err().
+func (v *Volcano) SolveElephant(ctx context.Context, used []string) ([]string, int, error) {
 	// Get all the targets that weren't attempted.
 	var targets []string
 	for _, name := range v.Nodes {
-		if v.IsClosed(name) && v.Rate(name) > 0 && !slices.Contains(used, name) {
+		if !v.Status[name] && v.Rate(name) > 0 && !slices.Contains(used, name) {
 			targets = append(targets, name)
 		}
 	}
@@ -588,6 +682,9 @@ func (v *Volcano) SolveElephant(used []string) ([]string, int) {
 	}
 
 	for len(queue) > 0 {
+		if ctx.Err() != nil {
+			return bestPath, bestScore, ctx.Err()
+		}
 		path := queue[0]
 		queue = queue[1:]
 
@@ -616,7 +713,7 @@ func (v *Volcano) SolveElephant(used []string) ([]string, int) {
 			queue = append(queue, tmp)
 		}
 	}
-	return bestPath, bestScore
+	return bestPath, bestScore, nil
 }
 
 func allUnique(s []string) bool {
@@ -629,3 +726,108 @@ func allUnique(s []string) bool {
 	}
 	return true
 }
+
+// Simulate starts a simulation of the greedy strategy in Volcano.Tick, one
+// minute at a time. Part 2 only has less time; the elephant doesn't help.
+func Simulate(part int, r io.Reader) (aoc22.Simulation, error) {
+	valves, err := Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	limit := 30
+	if part == 2 {
+		limit = 26
+	}
+	return &simulation{v: NewVolcano(valves, limit)}, nil
+}
+
+type simulation struct {
+	v        *Volcano
+	released int
+}
+
+func (s *simulation) Step() bool {
+	if s.v.TimeElapsed >= s.v.TimeLimit {
+		return false
+	}
+	s.released += s.v.Tick()
+	return true
+}
+
+func (s *simulation) Render() string {
+	return fmt.Sprintf("%d pressure released\n\n%s", s.released, s.v.Debug())
+}
+
+// Generate writes a connected network of size valves, with about a quarter
+// of them having a flow rate. Neither answer is known.
+func Generate(w io.Writer, rng *rand.Rand, size int) (aoc22.Generated, error) {
+	if size == 0 {
+		size = 57
+	}
+	if size < 2 || size > 26*26 {
+		return aoc22.Generated{}, fmt.Errorf("invalid size %d: need between 2 and %d valves", size, 26*26)
+	}
+
+	// Valve AA comes first, and the rest get distinct random names.
+	var names []string
+	for _, i := range rng.Perm(26 * 26) {
+		name := string([]byte{byte('A' + i/26), byte('A' + i%26)})
+		if name != "AA" {
+			names = append(names, name)
+		}
+	}
+	names = append([]string{"AA"}, names[:size-1]...)
+
+	// Join each valve to a random earlier one, so every valve can be
+	// reached, then add a few more tunnels to make loops.
+	tunnels := make(map[string][]string)
+	joined := make(map[[2]string]bool)
+	join := func(a, b string) {
+		if a == b || joined[[2]string{a, b}] {
+			return
+		}
+		joined[[2]string{a, b}], joined[[2]string{b, a}] = true, true
+		tunnels[a] = append(tunnels[a], b)
+		tunnels[b] = append(tunnels[b], a)
+	}
+	for i := 1; i < size; i++ {
+		join(names[i], names[rng.Intn(i)])
+	}
+	for i := 0; i < size/5; i++ {
+		join(names[rng.Intn(size)], names[rng.Intn(size)])
+	}
+
+	rates := make(map[string]int)
+	for _, i := range rng.Perm(size - 1)[:max(1, size/4)] {
+		rates[names[i+1]] = 1 + rng.Intn(25)
+	}
+
+	rng.Shuffle(len(names), func(i, j int) { names[i], names[j] = names[j], names[i] })
+
+	bw := bufio.NewWriter(w)
+	for _, name := range names {
+		ts := tunnels[name]
+		rng.Shuffle(len(ts), func(i, j int) { ts[i], ts[j] = ts[j], ts[i] })
+		if len(ts) == 1 {
+			fmt.Fprintf(bw, "Valve %s has flow rate=%d; tunnel leads to valve %s\n", name, rates[name], ts[0])
+		} else {
+			fmt.Fprintf(bw, "Valve %s has flow rate=%d; tunnels lead to valves %s\n", name, rates[name], strings.Join(ts, ", "))
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return aoc22.Generated{}, err
+	}
+	return aoc22.Generated{}, nil
+}
+
+func init() {
+	aoc22.Register(aoc22.Puzzle{
+		Day:   16,
+		Part1: func(ctx context.Context, r io.Reader, _ aoc22.Params) (aoc22.Answer, error) { return Part1(ctx, r) },
+		Part2: func(ctx context.Context, r io.Reader, _ aoc22.Params) (aoc22.Answer, error) { return Part2(ctx, r) },
+		Simulate: func(part int, r io.Reader, _ aoc22.Params) (aoc22.Simulation, error) {
+			return Simulate(part, r)
+		},
+		Generate: Generate,
+	})
+}
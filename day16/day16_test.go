@@ -1,9 +1,16 @@
 package day16
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"math/rand"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/clfs/aoc22"
 	"github.com/google/go-cmp/cmp"
 )
 
@@ -135,58 +142,24 @@ func TestVolcano_Path(t *testing.T) {
 }
 
 func TestPart1(t *testing.T) {
-	cases := []struct {
-		name string
-		want int
-	}{
-		{"testdata/small.txt", 1651},
-		{"testdata/input.txt", 2119},
-	}
-
-	for _, tc := range cases {
-		t.Run(tc.name, func(t *testing.T) {
-			f, err := os.Open(tc.name)
-			if err != nil {
-				t.Fatalf("failed to open %q: %v", tc.name, err)
-			}
-			defer f.Close()
-
-			got, err := Part1(f)
-			if err != nil {
-				t.Errorf("error: %v", err)
-			}
-			if got != tc.want {
-				t.Errorf("got %d, want %d", got, tc.want)
-			}
-		})
-	}
+	aoc22.CheckAnswers(t, 16, 1)
 }
 
 func TestPart2(t *testing.T) {
-	cases := []struct {
-		name string
-		want int
-	}{
-		{"testdata/small.txt", 1707},
-		//{"testdata/input.txt", 2615}, // very slow! 804s
-	}
+	aoc22.CheckAnswers(t, 16, 2)
+}
 
-	for _, tc := range cases {
-		t.Run(tc.name, func(t *testing.T) {
-			f, err := os.Open(tc.name)
-			if err != nil {
-				t.Fatalf("failed to open %q: %v", tc.name, err)
-			}
-			defer f.Close()
+func TestPart2_Timeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
 
-			got, err := Part2(f)
-			if err != nil {
-				t.Errorf("error: %v", err)
-			}
-			if got != tc.want {
-				t.Errorf("got %d, want %d", got, tc.want)
-			}
-		})
+	got, err := Part2(ctx, bytes.NewReader(aoc22.ReadTestFile(t, "testdata/input.txt")))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	// The full answer is 2615, and no partial answer can beat it.
+	if n, ok := got.Int64(); !ok || n <= 0 || n > 2615 {
+		t.Errorf("got %v, want a partial answer in (0, 2615]", got)
 	}
 }
 
@@ -215,3 +188,97 @@ func TestVolcano_Evaluate(t *testing.T) {
 		}
 	}
 }
+
+func TestParse_Error(t *testing.T) {
+	cases := []struct {
+		in        string
+		line, col int
+	}{
+		{"Valve AA has flow rate=0; tunnels lead to valves BB\nValve BB has flow rate=x; tunnel leads to valve AA\n", 2, 0},
+		{"Valve AA has flow rate=0; tunnels lead to valves BB, CC\nValve BB has flow rate=3; tunnel leads to valve AA\n", 1, 54},
+		{"Valve BB has flow rate=3; tunnel leads to valve BB\n", 0, 0},
+	}
+
+	for _, tc := range cases {
+		_, err := Parse(strings.NewReader(tc.in))
+
+		var pe *aoc22.ParseError
+		if !errors.As(err, &pe) {
+			t.Errorf("Parse(%q) error = %v, want a ParseError", tc.in, err)
+			continue
+		}
+		if pe.Day != 16 || pe.Line != tc.line || pe.Column != tc.col {
+			t.Errorf("Parse(%q) error = %v, want day 16 at %d:%d", tc.in, pe, tc.line, tc.col)
+		}
+	}
+}
+
+func TestVolcano_UnknownValve(t *testing.T) {
+	volcano := NewVolcano(readValves(t, "testdata/small.txt"), 30)
+
+	if err := volcano.Open("ZZ"); err == nil {
+		t.Error("Open(ZZ) succeeded, want an error")
+	}
+	if _, err := volcano.IsOpen("ZZ"); err == nil {
+		t.Error("IsOpen(ZZ) succeeded, want an error")
+	}
+	if _, err := volcano.NextFrom("ZZ"); err == nil {
+		t.Error("NextFrom(ZZ) succeeded, want an error")
+	}
+
+	if err := volcano.Open("BB"); err != nil {
+		t.Fatal(err)
+	}
+	if open, err := volcano.IsOpen("BB"); err != nil || !open {
+		t.Errorf("IsOpen(BB) = %t, %v after opening it", open, err)
+	}
+	if err := volcano.Open("BB"); err == nil {
+		t.Error("opening BB twice succeeded, want an error")
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	for _, size := range []int{2, 10, 30, 26 * 26} {
+		var buf bytes.Buffer
+		if _, err := Generate(&buf, rand.New(rand.NewSource(1)), size); err != nil {
+			t.Fatalf("size %d: %v", size, err)
+		}
+
+		valves, err := Parse(&buf)
+		if err != nil {
+			t.Fatalf("size %d: %v", size, err)
+		}
+		if len(valves) != size {
+			t.Errorf("size %d: got %d valves", size, len(valves))
+		}
+		v := NewVolcano(valves, 30)
+		for _, valve := range valves {
+			if v.LenPath("AA", valve.Name) == -1 {
+				t.Errorf("size %d: can't reach %s from AA", size, valve.Name)
+			}
+		}
+	}
+}
+
+func BenchmarkPart1(b *testing.B) {
+	data := aoc22.ReadTestFile(b, "testdata/input.txt")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Part1(context.Background(), bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPart2(b *testing.B) {
+	// The full input takes minutes.
+	data := aoc22.ReadTestFile(b, "testdata/small.txt")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Part2(context.Background(), bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
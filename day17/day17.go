@@ -0,0 +1,337 @@
+package day17
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/clfs/aoc22"
+)
+
+// ChamberWidth is the width of the chamber in units.
+const ChamberWidth = 7
+
+// A shape is a rock's rows from the bottom up. Bit x of a row is set if the
+// rock fills column x, counting from the rock's left edge.
+type shape []uint8
+
+// The rocks fall in this order, over and over.
+var shapes = []shape{
+	{0b1111},              // -
+	{0b010, 0b111, 0b010}, // +
+	{0b111, 0b100, 0b100}, // backwards L
+	{0b1, 0b1, 0b1, 0b1},  // |
+	{0b11, 0b11},          // square
+}
+
+// Parse parses the jet pattern. Each jet pushes a rock one unit left (-1) or
+// right (1).
+func Parse(r io.Reader) ([]int, error) {
+	var jets []int
+
+	s := bufio.NewScanner(r)
+	for line := 1; s.Scan(); line++ {
+		for i, c := range s.Text() {
+			switch c {
+			case '<':
+				jets = append(jets, -1)
+			case '>':
+				jets = append(jets, 1)
+			default:
+				return nil, &aoc22.ParseError{Day: 17, Line: line, Column: i + 1, Text: s.Text(), Err: fmt.Errorf("invalid jet %q", c)}
+			}
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(jets) == 0 {
+		return nil, &aoc22.ParseError{Day: 17, Err: errors.New("no jets")}
+	}
+	return jets, nil
+}
+
+// A Chamber is the tall, narrow chamber the rocks fall into.
+type Chamber struct {
+	rows  []uint8 // The settled rock from the floor up; bit x is column x.
+	jets  []int
+	jet   int   // The index of the next jet.
+	rocks int64 // How many rocks have fallen.
+}
+
+// NewChamber returns an empty chamber with the given jet pattern.
+func NewChamber(jets []int) *Chamber {
+	return &Chamber{jets: jets}
+}
+
+// Height returns the height of the tower of rocks.
+func (c *Chamber) Height() int {
+	return len(c.rows)
+}
+
+// Rocks returns how many rocks have fallen.
+func (c *Chamber) Rocks() int64 {
+	return c.rocks
+}
+
+// fits reports whether s fits with its bottom left corner at (x, y), without
+// hitting a wall, the floor, or settled rock.
+func (c *Chamber) fits(s shape, x, y int) bool {
+	if x < 0 || y < 0 {
+		return false
+	}
+	for i, row := range s {
+		bits := row << x
+		if bits>>ChamberWidth != 0 {
+			return false // The right wall.
+		}
+		if y+i < len(c.rows) && c.rows[y+i]&bits != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Drop drops the next rock, pushing it with the jets until it comes to rest.
+func (c *Chamber) Drop() {
+	c.drop()
+}
+
+// drop is Drop, but returns the row the bottom of the rock came to rest in.
+func (c *Chamber) drop() int {
+	s := shapes[c.rocks%int64(len(shapes))]
+
+	// Each rock appears two units from the left wall, with three empty
+	// rows below it.
+	x, y := 2, len(c.rows)+3
+	for {
+		if dx := c.jets[c.jet]; c.fits(s, x+dx, y) {
+			x += dx
+		}
+		c.jet = (c.jet + 1) % len(c.jets)
+
+		if !c.fits(s, x, y-1) {
+			break
+		}
+		y--
+	}
+
+	for i, row := range s {
+		if y+i == len(c.rows) {
+			c.rows = append(c.rows, 0)
+		}
+		c.rows[y+i] |= row << x
+	}
+	c.rocks++
+	return y
+}
+
+// A state is what decides how the rocks fall from some point on: the next
+// rock, the next jet, and the shape of the top of the tower, as long as no
+// rock falls further than stateDepth rows below the top.
+type state struct {
+	rock, jet int
+	top       string
+}
+
+// stateDepth is how many rows from the top of the tower a state holds.
+const stateDepth = 64
+
+// allAir is a row with nothing in it.
+const allAir = 1<<ChamberWidth - 1
+
+func (c *Chamber) state() state {
+	st := state{rock: int(c.rocks % int64(len(shapes))), jet: c.jet}
+
+	// Rocks only move left, right and down, so they can only ever fill the
+	// air reachable from above the tower that way. The top is that air, a
+	// row at a time from the top down; rock and air it can't reach don't
+	// matter.
+	var top []byte
+	open := uint8(allAir)
+	y := len(c.rows) - 1
+	for ; y >= 0 && len(top) < stateDepth && open != 0; y-- {
+		air := ^c.rows[y] & allAir
+		open = spread(open&air, air)
+		top = append(top, open)
+	}
+	if y < 0 && open != 0 {
+		top = append(top, 1<<ChamberWidth) // The air reaches the floor.
+	}
+
+	st.top = string(top)
+	return st
+}
+
+// spread returns the air in a row reachable from the cells in m by moving
+// left and right.
+func spread(m, air uint8) uint8 {
+	for {
+		next := (m | m<<1 | m>>1) & air
+		if next == m {
+			return m
+		}
+		m = next
+	}
+}
+
+// TowerHeight returns how tall the tower is after n rocks fall.
+//
+// Once a state repeats, and none of the rocks in between fell below the rows
+// it holds, the rocks fall the same way between the two times it happens as
+// they will forever after, so whole cycles are skipped.
+func TowerHeight(ctx context.Context, jets []int, n int64) (int64, error) {
+	c := NewChamber(jets)
+
+	// Part 2 drops far more rocks than fit in an int on 32-bit platforms.
+	type seen struct{ rocks, height int64 }
+	states := make(map[state]seen)
+	var rests []int // Where each rock came to rest, until a cycle is found.
+
+	var skipped int64 // The height of the skipped cycles.
+	for c.rocks < n {
+		// Checking every rock would dominate the loop.
+		if c.rocks%1024 == 0 && ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+
+		if states != nil {
+			st := c.state()
+			prev, ok := states[st]
+			// A state only holds the top of the tower, so it only marks a
+			// cycle if no rock since fell below that. A rock resting at y
+			// looked at the row below it.
+			deep := ok && slices.ContainsFunc(rests[prev.rocks:], func(y int) bool {
+				return y-1 < int(prev.height)-stateDepth
+			})
+			if ok && !deep {
+				length, growth := c.rocks-prev.rocks, int64(c.Height())-prev.height
+				cycles := (n - c.rocks) / length
+				skipped = cycles * growth
+				n -= cycles * length
+				states, rests = nil, nil // Only the leftover rocks are left to drop.
+			} else {
+				states[st] = seen{c.rocks, int64(c.Height())}
+			}
+		}
+
+		if c.rocks < n {
+			y := c.drop()
+			if states != nil {
+				rests = append(rests, y)
+			}
+		}
+	}
+
+	return int64(c.Height()) + skipped, nil
+}
+
+// Debug draws the rows between y0 and y1 inclusive, top first, with the walls
+// either side and the floor if y0 is 0:
+//
+//	|...#...|
+//	|..###..|
+//	|...#...|
+//	|..####.|
+//	+-------+
+func (c *Chamber) Debug(y0, y1 int) string {
+	var b strings.Builder
+	for y := y1; y >= y0; y-- {
+		var row uint8
+		if y >= 0 && y < len(c.rows) {
+			row = c.rows[y]
+		}
+
+		b.WriteByte('|')
+		for x := 0; x < ChamberWidth; x++ {
+			if row&(1<<x) != 0 {
+				b.WriteByte('#')
+			} else {
+				b.WriteByte('.')
+			}
+		}
+		b.WriteString("|\n")
+	}
+	if y0 <= 0 {
+		b.WriteString("+" + strings.Repeat("-", ChamberWidth) + "+\n")
+	}
+	return b.String()
+}
+
+func Part1(ctx context.Context, r io.Reader) (aoc22.Answer, error) {
+	jets, err := Parse(r)
+	if err != nil {
+		return aoc22.Answer{}, err
+	}
+
+	h, err := TowerHeight(ctx, jets, 2022)
+	if err != nil {
+		return aoc22.Answer{}, err
+	}
+	return aoc22.Int64(h), nil
+}
+
+func Part2(ctx context.Context, r io.Reader) (aoc22.Answer, error) {
+	jets, err := Parse(r)
+	if err != nil {
+		return aoc22.Answer{}, err
+	}
+
+	h, err := TowerHeight(ctx, jets, 1000000000000)
+	if err != nil {
+		return aoc22.Answer{}, err
+	}
+	return aoc22.Int64(h), nil
+}
+
+// Simulate starts a simulation of the rocks, one rock at a time. Both parts
+// fall the same way, only for longer in part 2.
+func Simulate(part int, r io.Reader) (aoc22.Simulation, error) {
+	jets, err := Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	limit := int64(2022)
+	if part == 2 {
+		limit = 1000000000000
+	}
+	return &simulation{c: NewChamber(jets), limit: limit}, nil
+}
+
+// The number of rows shown at the top of the tower.
+const simulationRows = 30
+
+type simulation struct {
+	c     *Chamber
+	limit int64
+}
+
+func (s *simulation) Step() bool {
+	if s.c.Rocks() >= s.limit {
+		return false
+	}
+	s.c.Drop()
+	return true
+}
+
+func (s *simulation) Render() string {
+	top := s.c.Height() + 3
+	return fmt.Sprintf("%d rocks, tower height %d\n\n%s", s.c.Rocks(), s.c.Height(),
+		s.c.Debug(max(0, top-simulationRows), top))
+}
+
+func init() {
+	aoc22.Register(aoc22.Puzzle{
+		Day:   17,
+		Part1: func(ctx context.Context, r io.Reader, _ aoc22.Params) (aoc22.Answer, error) { return Part1(ctx, r) },
+		Part2: func(ctx context.Context, r io.Reader, _ aoc22.Params) (aoc22.Answer, error) { return Part2(ctx, r) },
+		Simulate: func(part int, r io.Reader, _ aoc22.Params) (aoc22.Simulation, error) {
+			return Simulate(part, r)
+		},
+	})
+}
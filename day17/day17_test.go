@@ -0,0 +1,157 @@
+package day17
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/clfs/aoc22"
+)
+
+func TestParse_Error(t *testing.T) {
+	_, err := Parse(strings.NewReader(">><>x<\n"))
+
+	var pe *aoc22.ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("Parse() error = %v, want a ParseError", err)
+	}
+	if pe.Day != 17 || pe.Line != 1 || pe.Column != 5 {
+		t.Errorf("Parse() error = %v, want day 17 at 1:5", pe)
+	}
+}
+
+func TestChamber_Debug(t *testing.T) {
+	jets, err := Parse(bytes.NewReader(aoc22.ReadTestFile(t, "testdata/small.txt")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewChamber(jets)
+
+	// The tower after each of the first three rocks in the example.
+	want := []string{
+		`|..####.|
++-------+
+`,
+		`|...#...|
+|..###..|
+|...#...|
+|..####.|
++-------+
+`,
+		`|..#....|
+|..#....|
+|####...|
+|..###..|
+|...#...|
+|..####.|
++-------+
+`,
+	}
+	for i, w := range want {
+		c.Drop()
+		if got := c.Debug(0, c.Height()-1); got != w {
+			t.Errorf("after rock %d, got:\n%s\nwant:\n%s", i+1, got, w)
+		}
+	}
+}
+
+func TestTowerHeight(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+	}{
+		{"example", string(aoc22.ReadTestFile(t, "testdata/small.txt"))},
+		// Some columns are never filled, so the states must only hold the
+		// top of the tower for them to repeat.
+		{"all left", "<\n"},
+		{"all right", ">>>\n"},
+		// The tops of the columns repeat long before the tower does.
+		{"overhangs", ">>><>>>>><<>>><>>><><>><>><<><>><<><<<\n"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			jets, err := Parse(strings.NewReader(tc.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkTowerHeight(t, jets)
+
+			// And it must find a cycle, or part 2 would take forever.
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if _, err := TowerHeight(ctx, jets, 1000000000000); err != nil {
+				t.Errorf("TowerHeight(1000000000000) error = %v", err)
+			}
+		})
+	}
+}
+
+func TestTowerHeight_Random(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		// Jets that mostly blow one way leave overhangs.
+		right := rng.Float64()
+		jets := make([]int, 1+rng.Intn(50))
+		for j := range jets {
+			jets[j] = -1
+			if rng.Float64() < right {
+				jets[j] = 1
+			}
+		}
+		checkTowerHeight(t, jets)
+	}
+}
+
+// checkTowerHeight checks that skipping cycles gives the same height as
+// dropping every rock.
+func checkTowerHeight(t *testing.T, jets []int) {
+	t.Helper()
+	c := NewChamber(jets)
+	for _, n := range []int64{1, 10, 30, 100, 1000, 2022, 5000} {
+		for c.Rocks() < n {
+			c.Drop()
+		}
+		got, err := TowerHeight(context.Background(), jets, n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != int64(c.Height()) {
+			t.Errorf("jets %v: TowerHeight(%d) = %d, want %d", jets, n, got, c.Height())
+		}
+	}
+}
+
+func TestPart1(t *testing.T) {
+	aoc22.CheckAnswers(t, 17, 1)
+}
+
+func TestPart2(t *testing.T) {
+	aoc22.CheckAnswers(t, 17, 2)
+}
+
+func BenchmarkPart1(b *testing.B) {
+	data := aoc22.ReadTestFile(b, "testdata/small.txt")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Part1(context.Background(), bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPart2(b *testing.B) {
+	data := aoc22.ReadTestFile(b, "testdata/small.txt")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Part2(context.Background(), bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
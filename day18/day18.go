@@ -0,0 +1,121 @@
+package day18
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/clfs/aoc22"
+	"github.com/clfs/aoc22/geom"
+)
+
+// A Droplet is a set of 1x1x1 cubes, each named by its position.
+type Droplet map[geom.Point3]bool
+
+// Parse parses a droplet, one x,y,z cube per line.
+func Parse(r io.Reader) (Droplet, error) {
+	d := make(Droplet)
+
+	s := bufio.NewScanner(r)
+	for line := 1; s.Scan(); line++ {
+		nums, err := aoc22.ReadInts(s.Text())
+		if err != nil {
+			return nil, aoc22.NewParseError(18, line, s.Text(), err)
+		}
+		if len(nums) != 3 {
+			return nil, aoc22.NewParseError(18, line, s.Text(), fmt.Errorf("got %d coordinates, want 3", len(nums)))
+		}
+		d[geom.Pt3(nums[0], nums[1], nums[2])] = true
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(d) == 0 {
+		return nil, &aoc22.ParseError{Day: 18, Err: errors.New("no cubes")}
+	}
+	return d, nil
+}
+
+// SurfaceArea returns how many faces of the cubes don't touch another cube,
+// including faces on air pockets inside the droplet.
+func (d Droplet) SurfaceArea() int {
+	var n int
+	for p := range d {
+		for _, q := range p.Neighbors6() {
+			if !d[q] {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// ExteriorSurfaceArea returns how many faces of the cubes the steam outside
+// the droplet can reach.
+//
+// The steam fills a box one unit bigger than the droplet on every side, so it
+// can flow all the way around. Every time it runs into a cube, that's one
+// exterior face.
+func (d Droplet) ExteriorSurfaceArea(ctx context.Context) (int, error) {
+	cubes := make([]geom.Point3, 0, len(d))
+	for p := range d {
+		cubes = append(cubes, p)
+	}
+	box := geom.Bounds3(cubes).Expand(1)
+
+	var n int
+	steam := map[geom.Point3]bool{box.Min: true}
+	queue := []geom.Point3{box.Min}
+	for len(queue) > 0 {
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+
+		p := queue[0]
+		queue = queue[1:]
+		for _, q := range p.Neighbors6() {
+			switch {
+			case !box.Contains(q), steam[q]:
+			case d[q]:
+				n++
+			default:
+				steam[q] = true
+				queue = append(queue, q)
+			}
+		}
+	}
+	return n, nil
+}
+
+func Part1(ctx context.Context, r io.Reader) (aoc22.Answer, error) {
+	d, err := Parse(r)
+	if err != nil {
+		return aoc22.Answer{}, err
+	}
+
+	return aoc22.Int(d.SurfaceArea()), nil
+}
+
+func Part2(ctx context.Context, r io.Reader) (aoc22.Answer, error) {
+	d, err := Parse(r)
+	if err != nil {
+		return aoc22.Answer{}, err
+	}
+
+	n, err := d.ExteriorSurfaceArea(ctx)
+	if err != nil {
+		return aoc22.Answer{}, err
+	}
+	return aoc22.Int(n), nil
+}
+
+func init() {
+	aoc22.Register(aoc22.Puzzle{
+		Day:   18,
+		Part1: func(ctx context.Context, r io.Reader, _ aoc22.Params) (aoc22.Answer, error) { return Part1(ctx, r) },
+		Part2: func(ctx context.Context, r io.Reader, _ aoc22.Params) (aoc22.Answer, error) { return Part2(ctx, r) },
+	})
+}
@@ -0,0 +1,97 @@
+package day18
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/clfs/aoc22"
+)
+
+func TestParse_Error(t *testing.T) {
+	_, err := Parse(strings.NewReader("1,1,1\n2,1\n"))
+
+	var pe *aoc22.ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("Parse() error = %v, want a ParseError", err)
+	}
+	if pe.Day != 18 || pe.Line != 2 {
+		t.Errorf("Parse() error = %v, want day 18 on line 2", pe)
+	}
+}
+
+func TestDroplet_SurfaceArea(t *testing.T) {
+	// A 3x3x3 cube with the middle missing.
+	var shell []string
+	for x := 0; x < 3; x++ {
+		for y := 0; y < 3; y++ {
+			for z := 0; z < 3; z++ {
+				if x != 1 || y != 1 || z != 1 {
+					shell = append(shell, fmt.Sprintf("%d,%d,%d", x, y, z))
+				}
+			}
+		}
+	}
+
+	cases := []struct {
+		name              string
+		in                string
+		surface, exterior int
+	}{
+		{"one cube", "1,1,1", 6, 6},
+		{"two cubes", "1,1,1\n2,1,1", 10, 10},
+		{"hollow", strings.Join(shell, "\n"), 60, 54},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			d, err := Parse(strings.NewReader(tc.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := d.SurfaceArea(); got != tc.surface {
+				t.Errorf("SurfaceArea() = %d, want %d", got, tc.surface)
+			}
+			got, err := d.ExteriorSurfaceArea(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.exterior {
+				t.Errorf("ExteriorSurfaceArea() = %d, want %d", got, tc.exterior)
+			}
+		})
+	}
+}
+
+func TestPart1(t *testing.T) {
+	aoc22.CheckAnswers(t, 18, 1)
+}
+
+func TestPart2(t *testing.T) {
+	aoc22.CheckAnswers(t, 18, 2)
+}
+
+func BenchmarkPart1(b *testing.B) {
+	data := aoc22.ReadTestFile(b, "testdata/small.txt")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Part1(context.Background(), bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPart2(b *testing.B) {
+	data := aoc22.ReadTestFile(b, "testdata/small.txt")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Part2(context.Background(), bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,299 @@
+package day19
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/clfs/aoc22"
+)
+
+// The kinds of resource, and of the robots that collect them.
+const (
+	Ore = iota
+	Clay
+	Obsidian
+	Geode
+)
+
+// Resources holds an amount of each kind of resource, indexed by kind.
+type Resources [4]int
+
+// A Blueprint lists what each kind of robot costs to build.
+type Blueprint struct {
+	ID    int
+	Costs [4]Resources // Costs[k] is the cost of a robot that collects k.
+}
+
+// Parse parses blueprints, one per line.
+func Parse(r io.Reader) ([]Blueprint, error) {
+	var bps []Blueprint
+
+	s := bufio.NewScanner(r)
+	for line := 1; s.Scan(); line++ {
+		nums, err := aoc22.ReadInts(s.Text())
+		if err != nil {
+			return nil, aoc22.NewParseError(19, line, s.Text(), err)
+		}
+		if len(nums) != 7 {
+			return nil, aoc22.NewParseError(19, line, s.Text(), fmt.Errorf("got %d numbers, want 7", len(nums)))
+		}
+		bps = append(bps, Blueprint{
+			ID: nums[0],
+			Costs: [4]Resources{
+				Ore:      {Ore: nums[1]},
+				Clay:     {Ore: nums[2]},
+				Obsidian: {Ore: nums[3], Clay: nums[4]},
+				Geode:    {Ore: nums[5], Obsidian: nums[6]},
+			},
+		})
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(bps) == 0 {
+		return nil, &aoc22.ParseError{Day: 19, Err: errors.New("no blueprints")}
+	}
+	return bps, nil
+}
+
+// How many states to search between checks of the context.
+const checkEvery = 1 << 14
+
+// A search finds the most geodes one blueprint can open.
+type search struct {
+	ctx  context.Context
+	err  error
+	bp   Blueprint
+	caps Resources // The most robots of each kind worth building.
+
+	best   int
+	states int
+}
+
+// MaxGeodes returns the most geodes that bp can open in the given number of
+// minutes, starting with one ore robot, and how many states it searched to
+// find out.
+//
+// Rather than deciding what to do every minute, the search decides which
+// robot to build next and skips ahead to when it can be afforded. It never
+// builds more robots of a kind than any robot costs of that resource, since
+// only one robot can be built a minute, and it gives up on a branch once even
+// building a geode robot every remaining minute couldn't beat the best so
+// far.
+func MaxGeodes(ctx context.Context, bp Blueprint, minutes int) (geodes, states int, err error) {
+	s := &search{ctx: ctx, bp: bp}
+	for _, cost := range bp.Costs {
+		for k := Ore; k < Geode; k++ {
+			s.caps[k] = max(s.caps[k], cost[k])
+		}
+	}
+	s.dfs(minutes, Resources{Ore: 1}, Resources{})
+	return s.best, s.states, s.err
+}
+
+func (s *search) dfs(left int, robots, stock Resources) {
+	s.states++
+	if s.states%checkEvery == 0 && s.err == nil {
+		s.err = s.ctx.Err()
+	}
+	if s.err != nil {
+		return
+	}
+
+	// Building nothing more is always an option.
+	s.best = max(s.best, stock[Geode]+robots[Geode]*left)
+
+	// The best case is a new geode robot every minute from now on.
+	if stock[Geode]+robots[Geode]*left+left*(left-1)/2 <= s.best {
+		return
+	}
+
+	// Geode robots first, to find good answers early and prune more.
+	for k := Geode; k >= Ore; k-- {
+		// A robot is only worth building if its resource could still run
+		// short before the time is up.
+		if k != Geode && robots[k]*left+stock[k] >= s.caps[k]*left {
+			continue
+		}
+
+		wait, ok := s.wait(k, robots, stock)
+		if !ok || wait+1 >= left {
+			continue // It can't be built in time to collect anything.
+		}
+
+		next := stock
+		for i := range next {
+			next[i] += robots[i]*(wait+1) - s.bp.Costs[k][i]
+		}
+		more := robots
+		more[k]++
+		s.dfs(left-wait-1, more, next)
+	}
+}
+
+// wait returns how many minutes until a robot of kind k can be afforded, or
+// false if it never can with the robots there are.
+func (s *search) wait(k int, robots, stock Resources) (int, bool) {
+	var wait int
+	for i, cost := range s.bp.Costs[k] {
+		if cost <= stock[i] {
+			continue
+		}
+		if robots[i] == 0 {
+			return 0, false
+		}
+		wait = max(wait, (cost-stock[i]+robots[i]-1)/robots[i])
+	}
+	return wait, true
+}
+
+// A Result is how one blueprint did.
+type Result struct {
+	ID       int
+	Geodes   int
+	States   int           // How many states the search visited.
+	Duration time.Duration // How long the search took.
+}
+
+// Evaluate finds the most geodes each blueprint can open in the given number
+// of minutes, searching up to workers blueprints at once. If workers is less
+// than 1, it uses one per CPU. The results are in the same order as bps.
+//
+// If ctx carries a Tracer, it gets a "blueprint.done" event as each blueprint
+// finishes, to show which ones are slow.
+func Evaluate(ctx context.Context, bps []Blueprint, minutes, workers int) ([]Result, error) {
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = min(workers, len(bps))
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type done struct {
+		i   int
+		err error
+	}
+	var (
+		results = make([]Result, len(bps))
+		todo    = make(chan int)
+		dones   = make(chan done)
+		wg      sync.WaitGroup
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range todo {
+				start := time.Now()
+				geodes, states, err := MaxGeodes(ctx, bps[i], minutes)
+				results[i] = Result{bps[i].ID, geodes, states, time.Since(start)}
+				dones <- done{i, err}
+			}
+		}()
+	}
+	go func() {
+		defer close(todo)
+		for i := range bps {
+			select {
+			case todo <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(dones)
+	}()
+
+	// Only this goroutine traces, since tracers needn't be safe for
+	// concurrent use.
+	tr := aoc22.TracerFrom(ctx)
+	var err error
+	for d := range dones {
+		if d.err != nil {
+			if err == nil {
+				err = d.err
+			}
+			cancel()
+			continue
+		}
+		if tr != nil {
+			res := results[d.i]
+			tr.Trace("blueprint.done",
+				slog.Int("id", res.ID),
+				slog.Int("geodes", res.Geodes),
+				slog.Int("states", res.States),
+				slog.Duration("duration", res.Duration))
+		}
+	}
+	if err == nil {
+		// Stopping early leaves some blueprints unsearched.
+		err = ctx.Err()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func Part1(ctx context.Context, r io.Reader, workers int) (aoc22.Answer, error) {
+	bps, err := Parse(r)
+	if err != nil {
+		return aoc22.Answer{}, err
+	}
+
+	results, err := Evaluate(ctx, bps, 24, workers)
+	if err != nil {
+		return aoc22.Answer{}, err
+	}
+
+	var sum int
+	for _, res := range results {
+		sum += res.ID * res.Geodes
+	}
+	return aoc22.Int(sum), nil
+}
+
+func Part2(ctx context.Context, r io.Reader, workers int) (aoc22.Answer, error) {
+	bps, err := Parse(r)
+	if err != nil {
+		return aoc22.Answer{}, err
+	}
+
+	// The elephants ate all but the first three blueprints.
+	results, err := Evaluate(ctx, bps[:min(3, len(bps))], 32, workers)
+	if err != nil {
+		return aoc22.Answer{}, err
+	}
+
+	product := 1
+	for _, res := range results {
+		product *= res.Geodes
+	}
+	return aoc22.Int(product), nil
+}
+
+func init() {
+	aoc22.Register(aoc22.Puzzle{
+		Day: 19,
+		Part1: func(ctx context.Context, r io.Reader, params aoc22.Params) (aoc22.Answer, error) {
+			return Part1(ctx, r, params["workers"])
+		},
+		Part2: func(ctx context.Context, r io.Reader, params aoc22.Params) (aoc22.Answer, error) {
+			return Part2(ctx, r, params["workers"])
+		},
+		Params: []aoc22.Param{
+			{Name: "workers", Usage: "blueprints to search at once; 0 means one per CPU", Default: 0},
+		},
+	})
+}
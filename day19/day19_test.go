@@ -0,0 +1,101 @@
+package day19
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/clfs/aoc22"
+)
+
+func TestParse_Error(t *testing.T) {
+	_, err := Parse(strings.NewReader("Blueprint 1: Each ore robot costs 4 ore.\n"))
+
+	var pe *aoc22.ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("Parse() error = %v, want a ParseError", err)
+	}
+	if pe.Day != 19 || pe.Line != 1 {
+		t.Errorf("Parse() error = %v, want day 19 on line 1", pe)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	bps, err := Parse(bytes.NewReader(aoc22.ReadTestFile(t, "testdata/small.txt")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		minutes int
+		want    []int // The most geodes for each blueprint.
+	}{
+		{24, []int{9, 12}},
+		{32, []int{56, 62}},
+	}
+
+	for _, tc := range cases {
+		// The answers can't depend on how many blueprints are searched at
+		// once.
+		for _, workers := range []int{0, 1, 2} {
+			t.Run(fmt.Sprintf("%d minutes, %d workers", tc.minutes, workers), func(t *testing.T) {
+				results, err := Evaluate(context.Background(), bps, tc.minutes, workers)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for i, res := range results {
+					if res.ID != bps[i].ID || res.Geodes != tc.want[i] {
+						t.Errorf("results[%d] = blueprint %d with %d geodes, want blueprint %d with %d",
+							i, res.ID, res.Geodes, bps[i].ID, tc.want[i])
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestEvaluate_Canceled(t *testing.T) {
+	bps, err := Parse(bytes.NewReader(aoc22.ReadTestFile(t, "testdata/small.txt")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Evaluate(ctx, bps, 32, 1); !errors.Is(err, context.Canceled) {
+		t.Errorf("Evaluate() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestPart1(t *testing.T) {
+	aoc22.CheckAnswers(t, 19, 1)
+}
+
+func TestPart2(t *testing.T) {
+	aoc22.CheckAnswers(t, 19, 2)
+}
+
+func BenchmarkPart1(b *testing.B) {
+	data := aoc22.ReadTestFile(b, "testdata/small.txt")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Part1(context.Background(), bytes.NewReader(data), 0); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPart2(b *testing.B) {
+	data := aoc22.ReadTestFile(b, "testdata/small.txt")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Part2(context.Background(), bytes.NewReader(data), 0); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
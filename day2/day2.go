@@ -2,8 +2,11 @@ package day2
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"io"
+
+	"github.com/clfs/aoc22"
 )
 
 const (
@@ -19,10 +22,25 @@ type Round struct {
 	Opponent, Me rune
 }
 
-func (r *Round) UnmarshalText(text []byte) error {
-	if len(text) < 3 {
+// checkRound reports whether text holds an opponent's move, a space, and one
+// of X, Y, or Z.
+func checkRound(text []byte) error {
+	if len(text) < 3 || text[1] != ' ' {
 		return fmt.Errorf("invalid round: %s", string(text))
 	}
+	if text[0] < OppRock || text[0] > OppScissors {
+		return &aoc22.ParseError{Column: 1, Err: fmt.Errorf("invalid opponent move %q", text[0])}
+	}
+	if text[2] < 'X' || text[2] > 'Z' {
+		return &aoc22.ParseError{Column: 3, Err: fmt.Errorf("invalid response %q", text[2])}
+	}
+	return nil
+}
+
+func (r *Round) UnmarshalText(text []byte) error {
+	if err := checkRound(text); err != nil {
+		return err
+	}
 
 	r.Opponent = rune(text[0])
 	r.Me = rune(text[2])
@@ -69,8 +87,8 @@ const (
 )
 
 func (r *RoundAlt) UnmarshalText(text []byte) error {
-	if len(text) < 3 {
-		return fmt.Errorf("invalid round: %s", string(text))
+	if err := checkRound(text); err != nil {
+		return err
 	}
 
 	r.Opponent = rune(text[0])
@@ -107,58 +125,70 @@ func (r RoundAlt) Score() int {
 	return score
 }
 
-func parse(r io.Reader) []Round {
+func parse(r io.Reader) ([]Round, error) {
 	var result []Round
 
 	scanner := bufio.NewScanner(r)
-	for scanner.Scan() {
+	for line := 1; scanner.Scan(); line++ {
 		var rd Round
 		if err := rd.UnmarshalText(scanner.Bytes()); err != nil {
-			panic(err)
+			return nil, aoc22.NewParseError(2, line, scanner.Text(), err)
 		}
 		result = append(result, rd)
 	}
 
-	if err := scanner.Err(); err != nil {
-		panic(err)
-	}
-	return result
+	return result, scanner.Err()
 }
 
-func parseAlt(r io.Reader) []RoundAlt {
+func parseAlt(r io.Reader) ([]RoundAlt, error) {
 	var result []RoundAlt
 
 	scanner := bufio.NewScanner(r)
-	for scanner.Scan() {
+	for line := 1; scanner.Scan(); line++ {
 		var rd RoundAlt
 		if err := rd.UnmarshalText(scanner.Bytes()); err != nil {
-			panic(err)
+			return nil, aoc22.NewParseError(2, line, scanner.Text(), err)
 		}
 		result = append(result, rd)
 	}
 
-	if err := scanner.Err(); err != nil {
-		panic(err)
-	}
-	return result
+	return result, scanner.Err()
 }
 
-func Part1(r io.Reader) int {
+func Part1(ctx context.Context, r io.Reader) (aoc22.Answer, error) {
+	rounds, err := parse(r)
+	if err != nil {
+		return aoc22.Answer{}, err
+	}
+
 	var score int
 
-	for _, rd := range parse(r) {
+	for _, rd := range rounds {
 		score += rd.Score()
 	}
 
-	return score
+	return aoc22.Int(score), nil
 }
 
-func Part2(r io.Reader) int {
+func Part2(ctx context.Context, r io.Reader) (aoc22.Answer, error) {
+	rounds, err := parseAlt(r)
+	if err != nil {
+		return aoc22.Answer{}, err
+	}
+
 	var score int
 
-	for _, rd := range parseAlt(r) {
+	for _, rd := range rounds {
 		score += rd.Score()
 	}
 
-	return score
+	return aoc22.Int(score), nil
+}
+
+func init() {
+	aoc22.Register(aoc22.Puzzle{
+		Day:   2,
+		Part1: func(ctx context.Context, r io.Reader, _ aoc22.Params) (aoc22.Answer, error) { return Part1(ctx, r) },
+		Part2: func(ctx context.Context, r io.Reader, _ aoc22.Params) (aoc22.Answer, error) { return Part2(ctx, r) },
+	})
 }
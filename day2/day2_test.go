@@ -2,31 +2,20 @@ package day2
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/clfs/aoc22"
 )
 
 func TestPart1(t *testing.T) {
-	data := aoc22.ReadTestFile(t, "testdata/input.txt")
-
-	got := Part1(bytes.NewReader(data))
-	want := 15523
-
-	if got != want {
-		t.Errorf("Part1() = %d, want %d", got, want)
-	}
+	aoc22.CheckAnswers(t, 2, 1)
 }
 
 func TestPart2(t *testing.T) {
-	data := aoc22.ReadTestFile(t, "testdata/input.txt")
-
-	got := Part2(bytes.NewReader(data))
-	want := 15702
-
-	if got != want {
-		t.Errorf("Part2() = %d, want %d", got, want)
-	}
+	aoc22.CheckAnswers(t, 2, 2)
 }
 
 func TestRound(t *testing.T) {
@@ -82,3 +71,50 @@ func TestRoundAlt(t *testing.T) {
 		}
 	}
 }
+
+func TestParse_Error(t *testing.T) {
+	cases := []struct {
+		in         string
+		line, col  int
+		wantColumn bool
+	}{
+		{"A X\nB Y\nD Z\n", 3, 1, true},
+		{"A X\nB W\n", 2, 3, true},
+		{"A X\nBY\n", 2, 0, false},
+	}
+
+	for _, tc := range cases {
+		_, err := Part1(context.Background(), strings.NewReader(tc.in))
+
+		var pe *aoc22.ParseError
+		if !errors.As(err, &pe) {
+			t.Errorf("Part1(%q) error = %v, want a ParseError", tc.in, err)
+			continue
+		}
+		if pe.Line != tc.line || pe.Column != tc.col {
+			t.Errorf("Part1(%q) error at %d:%d, want %d:%d", tc.in, pe.Line, pe.Column, tc.line, tc.col)
+		}
+	}
+}
+
+func BenchmarkPart1(b *testing.B) {
+	data := aoc22.ReadTestFile(b, "testdata/input.txt")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Part1(context.Background(), bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPart2(b *testing.B) {
+	data := aoc22.ReadTestFile(b, "testdata/input.txt")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Part2(context.Background(), bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
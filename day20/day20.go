@@ -0,0 +1,277 @@
+package day20
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/clfs/aoc22"
+)
+
+// DecryptionKey is what every number is multiplied by in part 2.
+const DecryptionKey = 811589153
+
+// Parse parses the encrypted file, one number per line. Exactly one of the
+// numbers must be zero, since the coordinates are found from it.
+func Parse(r io.Reader) ([]int, error) {
+	var (
+		nums  []int
+		zeros int
+	)
+
+	s := bufio.NewScanner(r)
+	for line := 1; s.Scan(); line++ {
+		n, err := strconv.Atoi(strings.TrimSpace(s.Text()))
+		if err != nil {
+			return nil, aoc22.NewParseError(20, line, s.Text(), err)
+		}
+		if n == 0 {
+			zeros++
+			if zeros > 1 {
+				return nil, aoc22.NewParseError(20, line, s.Text(), errors.New("more than one zero"))
+			}
+		}
+		nums = append(nums, n)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	if zeros == 0 {
+		return nil, &aoc22.ParseError{Day: 20, Err: errors.New("no zero")}
+	}
+	return nums, nil
+}
+
+// A File is the circular list of numbers being mixed.
+//
+// It's kept as an implicit treap: a balanced tree whose in-order traversal is
+// the list, with each node knowing the size of its subtree. That finds a
+// number's position and moves it in O(log n), where shifting a slice would
+// take O(n).
+type File struct {
+	root  *node
+	nodes []*node // In the original order, which is the order they move in.
+	zero  *node
+}
+
+type node struct {
+	value               int64 // Multiplied by the key, it needn't fit in 32 bits.
+	priority            int64 // Heap ordered, largest at the root.
+	size                int
+	left, right, parent *node
+}
+
+func size(n *node) int {
+	if n == nil {
+		return 0
+	}
+	return n.size
+}
+
+// update recomputes n's size and points its children back at it.
+func (n *node) update() {
+	n.size = 1 + size(n.left) + size(n.right)
+	if n.left != nil {
+		n.left.parent = n
+	}
+	if n.right != nil {
+		n.right.parent = n
+	}
+}
+
+// merge joins the lists a and b, in that order.
+func merge(a, b *node) *node {
+	switch {
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	case a.priority > b.priority:
+		a.right = merge(a.right, b)
+		a.update()
+		return a
+	default:
+		b.left = merge(a, b.left)
+		b.update()
+		return b
+	}
+}
+
+// split splits the list t into its first k nodes and the rest. The roots it
+// returns have stale parents; callers reset them.
+func split(t *node, k int) (*node, *node) {
+	if t == nil {
+		return nil, nil
+	}
+	if size(t.left) >= k {
+		l, r := split(t.left, k)
+		t.left = r
+		t.update()
+		return l, t
+	}
+	l, r := split(t.right, k-size(t.left)-1)
+	t.right = l
+	t.update()
+	return t, r
+}
+
+// index returns n's position in the list.
+func (f *File) index(n *node) int {
+	i := size(n.left)
+	for ; n != f.root; n = n.parent {
+		if n == n.parent.right {
+			i += size(n.parent.left) + 1
+		}
+	}
+	return i
+}
+
+// at returns the node at position i in the list.
+func (f *File) at(i int) *node {
+	n := f.root
+	for {
+		switch l := size(n.left); {
+		case i < l:
+			n = n.left
+		case i == l:
+			return n
+		default:
+			i -= l + 1
+			n = n.right
+		}
+	}
+}
+
+func (f *File) setRoot(n *node) {
+	f.root = n
+	n.parent = nil
+}
+
+// NewFile returns a file holding nums, each multiplied by key.
+func NewFile(nums []int, key int64) *File {
+	// A fixed seed keeps the tree's shape, and so the running time, the same
+	// from run to run.
+	rng := rand.New(rand.NewSource(1))
+
+	f := &File{nodes: make([]*node, len(nums))}
+	for i, v := range nums {
+		n := &node{value: int64(v) * key, priority: rng.Int63(), size: 1}
+		f.nodes[i] = n
+		if v == 0 {
+			f.zero = n
+		}
+		f.root = merge(f.root, n)
+	}
+	if f.root != nil {
+		f.root.parent = nil
+	}
+	return f
+}
+
+// Len returns how many numbers are in the file.
+func (f *File) Len() int {
+	return len(f.nodes)
+}
+
+// Mix moves each number, in the order they were first in, forward or back by
+// its value. The list is circular, so a number moving past either end wraps
+// around to the other.
+func (f *File) Mix() {
+	// With the number itself out of the list, there are Len-1 others to
+	// step past before coming back to the same place.
+	others := f.Len() - 1
+	if others < 1 {
+		return
+	}
+
+	for _, n := range f.nodes {
+		i := f.index(n)
+
+		l, r := split(f.root, i)
+		_, r = split(r, 1)
+		n.left, n.right = nil, nil
+		n.update()
+
+		j := int(((int64(i)+n.value)%int64(others) + int64(others)) % int64(others))
+		l, r = split(merge(l, r), j)
+		f.setRoot(merge(merge(l, n), r))
+	}
+}
+
+// Values returns the numbers in the file in order, starting from zero.
+func (f *File) Values() []int64 {
+	vals := make([]int64, 0, f.Len())
+	var walk func(n *node)
+	walk = func(n *node) {
+		if n == nil {
+			return
+		}
+		walk(n.left)
+		vals = append(vals, n.value)
+		walk(n.right)
+	}
+	walk(f.root)
+
+	i := f.index(f.zero)
+	return append(vals[i:], vals[:i]...)
+}
+
+// Coordinates returns the 1000th, 2000th and 3000th numbers after zero,
+// wrapping around the list as needed.
+func (f *File) Coordinates() [3]int64 {
+	i := f.index(f.zero)
+	var coords [3]int64
+	for k := range coords {
+		coords[k] = f.at((i + (k+1)*1000) % f.Len()).value
+	}
+	return coords
+}
+
+// decrypt mixes the file the given number of times, and returns the sum of
+// its coordinates.
+func decrypt(ctx context.Context, nums []int, key int64, rounds int) (aoc22.Answer, error) {
+	f := NewFile(nums, key)
+	for i := 0; i < rounds; i++ {
+		if err := ctx.Err(); err != nil {
+			return aoc22.Answer{}, err
+		}
+		f.Mix()
+	}
+
+	var sum int64
+	for _, c := range f.Coordinates() {
+		sum += c
+	}
+	return aoc22.Int64(sum), nil
+}
+
+func Part1(ctx context.Context, r io.Reader) (aoc22.Answer, error) {
+	nums, err := Parse(r)
+	if err != nil {
+		return aoc22.Answer{}, err
+	}
+
+	return decrypt(ctx, nums, 1, 1)
+}
+
+func Part2(ctx context.Context, r io.Reader) (aoc22.Answer, error) {
+	nums, err := Parse(r)
+	if err != nil {
+		return aoc22.Answer{}, err
+	}
+
+	return decrypt(ctx, nums, DecryptionKey, 10)
+}
+
+func init() {
+	aoc22.Register(aoc22.Puzzle{
+		Day:   20,
+		Part1: func(ctx context.Context, r io.Reader, _ aoc22.Params) (aoc22.Answer, error) { return Part1(ctx, r) },
+		Part2: func(ctx context.Context, r io.Reader, _ aoc22.Params) (aoc22.Answer, error) { return Part2(ctx, r) },
+	})
+}
@@ -0,0 +1,143 @@
+package day20
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/clfs/aoc22"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParse_Error(t *testing.T) {
+	cases := []struct {
+		in   string
+		line int
+	}{
+		{"1\n2-3\n0\n", 2},
+		{"0\n1\n0\n", 3},
+		{"1\n2\n", 0},
+	}
+
+	for _, tc := range cases {
+		_, err := Parse(strings.NewReader(tc.in))
+
+		var pe *aoc22.ParseError
+		if !errors.As(err, &pe) {
+			t.Errorf("Parse(%q) error = %v, want a ParseError", tc.in, err)
+			continue
+		}
+		if pe.Day != 20 || pe.Line != tc.line {
+			t.Errorf("Parse(%q) error = %v, want day 20 on line %d", tc.in, pe, tc.line)
+		}
+	}
+}
+
+func TestFile_Mix(t *testing.T) {
+	nums, err := Parse(bytes.NewReader(aoc22.ReadTestFile(t, "testdata/small.txt")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := NewFile(nums, 1)
+	f.Mix()
+	if diff := cmp.Diff([]int64{0, 3, -2, 1, 2, -3, 4}, f.Values()); diff != "" {
+		t.Errorf("Values() after Mix() mismatch (-want +got):\n%s", diff)
+	}
+	if got, want := f.Coordinates(), [3]int64{4, -3, 2}; got != want {
+		t.Errorf("Coordinates() = %v, want %v", got, want)
+	}
+}
+
+// naiveMix mixes vals by moving each number along a slice. The numbers are
+// told apart by their original positions, since they may repeat.
+func naiveMix(vals []int64, rounds int) []int64 {
+	order := make([]int, len(vals))
+	for i := range order {
+		order[i] = i
+	}
+	for ; rounds > 0; rounds-- {
+		for i, v := range vals {
+			j := slices.Index(order, i)
+			order = slices.Delete(order, j, j+1)
+			n := int64(len(order))
+			k := int(((int64(j)+v)%n + n) % n)
+			order = slices.Insert(order, k, i)
+		}
+	}
+
+	mixed := make([]int64, len(order))
+	for i, o := range order {
+		mixed[i] = vals[o]
+	}
+	z := slices.Index(mixed, 0)
+	return append(mixed[z:], mixed[:z]...)
+}
+
+func TestFile_MixMatchesNaive(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		// Few distinct numbers, so plenty of them repeat, and some are
+		// much bigger than the list.
+		var nums []int
+		for n := 1 + rng.Intn(30); n > 0; {
+			v := rng.Intn(9) - 4 + rng.Intn(2)*(rng.Intn(200)-100)
+			if v != 0 {
+				nums = append(nums, v)
+				n--
+			}
+		}
+		nums = append(nums, 0)
+		rounds := 1 + rng.Intn(3)
+		key := int64(1)
+		if rng.Intn(2) == 0 {
+			key = DecryptionKey
+		}
+
+		vals := make([]int64, len(nums))
+		for i, v := range nums {
+			vals[i] = int64(v) * key
+		}
+		f := NewFile(nums, key)
+		for r := 0; r < rounds; r++ {
+			f.Mix()
+		}
+		if diff := cmp.Diff(naiveMix(vals, rounds), f.Values()); diff != "" {
+			t.Fatalf("mixing %v with key %d %d times mismatch (-naive +got):\n%s", nums, key, rounds, diff)
+		}
+	}
+}
+
+func TestPart1(t *testing.T) {
+	aoc22.CheckAnswers(t, 20, 1)
+}
+
+func TestPart2(t *testing.T) {
+	aoc22.CheckAnswers(t, 20, 2)
+}
+
+func BenchmarkPart1(b *testing.B) {
+	data := aoc22.ReadTestFile(b, "testdata/small.txt")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Part1(context.Background(), bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPart2(b *testing.B) {
+	data := aoc22.ReadTestFile(b, "testdata/small.txt")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Part2(context.Background(), bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
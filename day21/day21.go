@@ -0,0 +1,382 @@
+package day21
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/clfs/aoc22"
+)
+
+// The monkeys that matter.
+const (
+	Root  = "root"
+	Human = "humn"
+)
+
+// A Monkey either yells a number, or waits for two other monkeys and yells the
+// result of an operation on theirs.
+type Monkey struct {
+	Number      int
+	Op          byte // One of + - * /, or 0 if the monkey yells Number.
+	Left, Right string
+}
+
+// A Troop maps each monkey's name to the monkey. The monkeys waiting on each
+// other form a DAG: one monkey can be waited on by many, but none waits on
+// itself, even through others.
+type Troop map[string]Monkey
+
+// Parse parses the monkeys, one per line, like "root: pppw + sjmn" or
+// "dbpl: 5". Every monkey waited on must be in the input, and no monkey may
+// wait on itself.
+func Parse(r io.Reader) (Troop, error) {
+	t := make(Troop)
+	lines := make(map[string]int) // Where each monkey is, for errors.
+
+	s := bufio.NewScanner(r)
+	for line := 1; s.Scan(); line++ {
+		name, job, ok := strings.Cut(s.Text(), ": ")
+		if !ok {
+			return nil, aoc22.NewParseError(21, line, s.Text(), errors.New(`missing ": "`))
+		}
+		if _, ok := t[name]; ok {
+			return nil, aoc22.NewParseError(21, line, s.Text(), fmt.Errorf("monkey %s already listed", name))
+		}
+
+		var m Monkey
+		switch f := strings.Fields(job); {
+		case len(f) == 1:
+			n, err := strconv.Atoi(f[0])
+			if err != nil {
+				return nil, aoc22.NewParseError(21, line, s.Text(), err)
+			}
+			m.Number = n
+		case len(f) == 3 && len(f[1]) == 1 && strings.Contains("+-*/", f[1]):
+			m.Left, m.Op, m.Right = f[0], f[1][0], f[2]
+		default:
+			return nil, aoc22.NewParseError(21, line, s.Text(), fmt.Errorf("invalid job %q", job))
+		}
+		t[name] = m
+		lines[name] = line
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	for name, m := range t {
+		if m.Op == 0 {
+			continue
+		}
+		for _, other := range []string{m.Left, m.Right} {
+			if _, ok := t[other]; !ok {
+				return nil, &aoc22.ParseError{Day: 21, Line: lines[name], Err: fmt.Errorf("monkey %s waits on missing monkey %s", name, other)}
+			}
+		}
+	}
+	if _, ok := t[Root]; !ok {
+		return nil, &aoc22.ParseError{Day: 21, Err: errors.New("no root monkey")}
+	}
+	if loop := t.loop(); loop != nil {
+		return nil, &aoc22.ParseError{Day: 21, Line: lines[loop[0]], Err: fmt.Errorf("monkey %s waits on itself: %s", loop[0], strings.Join(loop, " -> "))}
+	}
+	return t, nil
+}
+
+// loop returns the names of monkeys that wait on each other in a loop, like
+// [a b a], or nil if none do.
+func (t Troop) loop() []string {
+	const (
+		visiting = 1 // On the path being followed.
+		visited  = 2 // Known not to be in a loop.
+	)
+	var (
+		state = make(map[string]int)
+		path  []string
+	)
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		switch state[name] {
+		case visiting:
+			return append(path[slices.Index(path, name):], name)
+		case visited:
+			return nil
+		}
+
+		state[name] = visiting
+		path = append(path, name)
+		if m := t[name]; m.Op != 0 {
+			for _, other := range []string{m.Left, m.Right} {
+				if loop := visit(other); loop != nil {
+					return loop
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+		return nil
+	}
+
+	// In order, so the same input always reports the same loop.
+	names := make([]string, 0, len(t))
+	for name := range t {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if loop := visit(name); loop != nil {
+			return loop
+		}
+	}
+	return nil
+}
+
+// An evaluator works out what monkeys yell, remembering each result since
+// many monkeys can wait on the same one.
+type evaluator struct {
+	t      Troop
+	values map[string]*big.Rat
+}
+
+// Eval returns the number the named monkey yells. The arithmetic is exact: a
+// division that doesn't come out whole gives a fraction.
+func (t Troop) Eval(name string) (*big.Rat, error) {
+	e := &evaluator{t: t, values: make(map[string]*big.Rat)}
+	return e.eval(name)
+}
+
+func (e *evaluator) eval(name string) (*big.Rat, error) {
+	if v, ok := e.values[name]; ok {
+		return v, nil
+	}
+	m, ok := e.t[name]
+	if !ok {
+		return nil, fmt.Errorf("no monkey %s", name)
+	}
+	if m.Op == 0 {
+		v := new(big.Rat).SetInt64(int64(m.Number))
+		e.values[name] = v
+		return v, nil
+	}
+
+	l, err := e.eval(m.Left)
+	if err != nil {
+		return nil, err
+	}
+	r, err := e.eval(m.Right)
+	if err != nil {
+		return nil, err
+	}
+
+	v := new(big.Rat)
+	switch m.Op {
+	case '+':
+		v.Add(l, r)
+	case '-':
+		v.Sub(l, r)
+	case '*':
+		v.Mul(l, r)
+	case '/':
+		if r.Sign() == 0 {
+			return nil, fmt.Errorf("monkey %s divides by zero", name)
+		}
+		v.Quo(l, r)
+	}
+	e.values[name] = v
+	return v, nil
+}
+
+// dependsOn reports whether the named monkey's number depends on the unknown
+// monkey's, counting how many paths reach the unknown one, up to 2.
+func (t Troop) dependsOn(name, unknown string, memo map[string]int) int {
+	if name == unknown {
+		return 1
+	}
+	if n, ok := memo[name]; ok {
+		return n
+	}
+
+	var n int
+	if m := t[name]; m.Op != 0 {
+		n = min(2, t.dependsOn(m.Left, unknown, memo)+t.dependsOn(m.Right, unknown, memo))
+	}
+	memo[name] = n
+	return n
+}
+
+// Solve returns the number the unknown monkey must yell for the two monkeys
+// root waits on to yell the same number.
+//
+// Starting from root, it evaluates the side that doesn't depend on the
+// unknown, then undoes each operation on the way down to it: if x+3 must be
+// 10, x must be 7. That only works if the unknown is reached by exactly one
+// path, so it returns an error otherwise.
+func (t Troop) Solve(unknown string) (*big.Rat, error) {
+	root := t[Root]
+	if root.Op == 0 {
+		return nil, errors.New("root monkey doesn't wait on others")
+	}
+	if _, ok := t[unknown]; !ok {
+		return nil, fmt.Errorf("no monkey %s", unknown)
+	}
+	if n := t.dependsOn(Root, unknown, make(map[string]int)); n != 1 {
+		return nil, fmt.Errorf("root depends on %s by %d paths, want 1", unknown, n)
+	}
+
+	e := &evaluator{t: t, values: make(map[string]*big.Rat)}
+	memo := make(map[string]int)
+
+	// At root, both sides must be equal. Below it, target is what the
+	// monkey named must yell.
+	var (
+		name   string
+		target *big.Rat
+	)
+	if t.dependsOn(root.Left, unknown, memo) > 0 {
+		v, err := e.eval(root.Right)
+		if err != nil {
+			return nil, err
+		}
+		name, target = root.Left, v
+	} else {
+		v, err := e.eval(root.Left)
+		if err != nil {
+			return nil, err
+		}
+		name, target = root.Right, v
+	}
+
+	for name != unknown {
+		m := t[name]
+		left := t.dependsOn(m.Left, unknown, memo) > 0
+		known := m.Left
+		if left {
+			known = m.Right
+		}
+		k, err := e.eval(known)
+		if err != nil {
+			return nil, err
+		}
+
+		x := new(big.Rat)
+		switch {
+		case m.Op == '+': // x+k = target, k+x = target
+			x.Sub(target, k)
+		case m.Op == '-' && left: // x-k = target
+			x.Add(target, k)
+		case m.Op == '-': // k-x = target
+			x.Sub(k, target)
+		case m.Op == '*': // x*k = target, k*x = target
+			if k.Sign() == 0 {
+				return nil, fmt.Errorf("monkey %s multiplies by zero, so %s could be anything", name, unknown)
+			}
+			x.Quo(target, k)
+		case m.Op == '/' && left: // x/k = target
+			if k.Sign() == 0 {
+				return nil, fmt.Errorf("monkey %s divides by zero", name)
+			}
+			x.Mul(target, k)
+		default: // k/x = target
+			if target.Sign() == 0 {
+				return nil, fmt.Errorf("monkey %s can't yell 0 by dividing %s by anything", name, k.RatString())
+			}
+			x.Quo(k, target)
+		}
+
+		if left {
+			name = m.Left
+		} else {
+			name = m.Right
+		}
+		target = x
+	}
+	return target, nil
+}
+
+// Infix returns the named monkey's job as a fully bracketed expression, like
+// "((4 + (2 * (humn - 3))) / 4)". Monkeys named in vars are written by name;
+// all others that yell numbers are written as their numbers.
+func (t Troop) Infix(name string, vars ...string) string {
+	var b strings.Builder
+	t.infix(&b, name, vars)
+	return b.String()
+}
+
+func (t Troop) infix(b *strings.Builder, name string, vars []string) {
+	for _, v := range vars {
+		if name == v {
+			b.WriteString(name)
+			return
+		}
+	}
+
+	m := t[name]
+	if m.Op == 0 {
+		b.WriteString(strconv.Itoa(m.Number))
+		return
+	}
+	b.WriteByte('(')
+	t.infix(b, m.Left, vars)
+	fmt.Fprintf(b, " %c ", m.Op)
+	t.infix(b, m.Right, vars)
+	b.WriteByte(')')
+}
+
+// Equation returns the equation that part 2 solves, with root's operation
+// replaced by "=" and the human written as humn.
+func (t Troop) Equation() string {
+	root := t[Root]
+	return t.Infix(root.Left, Human) + " = " + t.Infix(root.Right, Human)
+}
+
+// answer converts a whole number to an Answer.
+func answer(v *big.Rat) (aoc22.Answer, error) {
+	if !v.IsInt() {
+		return aoc22.Answer{}, fmt.Errorf("%s isn't a whole number", v.RatString())
+	}
+	return aoc22.BigInt(v.Num()), nil
+}
+
+func Part1(ctx context.Context, r io.Reader) (aoc22.Answer, error) {
+	t, err := Parse(r)
+	if err != nil {
+		return aoc22.Answer{}, err
+	}
+
+	v, err := t.Eval(Root)
+	if err != nil {
+		return aoc22.Answer{}, err
+	}
+	return answer(v)
+}
+
+func Part2(ctx context.Context, r io.Reader) (aoc22.Answer, error) {
+	t, err := Parse(r)
+	if err != nil {
+		return aoc22.Answer{}, err
+	}
+
+	v, err := t.Solve(Human)
+	if err != nil {
+		return aoc22.Answer{}, err
+	}
+	if tr := aoc22.TracerFrom(ctx); tr != nil {
+		tr.Trace("equation", slog.String("equation", t.Equation()), slog.String("humn", v.RatString()))
+	}
+	return answer(v)
+}
+
+func init() {
+	aoc22.Register(aoc22.Puzzle{
+		Day:   21,
+		Part1: func(ctx context.Context, r io.Reader, _ aoc22.Params) (aoc22.Answer, error) { return Part1(ctx, r) },
+		Part2: func(ctx context.Context, r io.Reader, _ aoc22.Params) (aoc22.Answer, error) { return Part2(ctx, r) },
+	})
+}
@@ -0,0 +1,147 @@
+package day21
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/clfs/aoc22"
+)
+
+func TestParse_Error(t *testing.T) {
+	cases := []struct {
+		in   string
+		line int
+	}{
+		{"root: a + b\na: 1\nb 2\n", 3},
+		{"root: a % b\na: 1\nb: 2\n", 1},
+		{"root: a + b\na: 1\na: 2\n", 3},
+		{"root: a + b\na: 1\n", 1},
+		{"a: 1\n", 0},
+		{"root: a + c\na: b + b\nb: a + a\nc: 1\n", 2},
+		{"root: a + b\na: root - b\nb: 1\n", 2},
+		{"root: root + a\na: 1\n", 1},
+	}
+
+	for _, tc := range cases {
+		_, err := Parse(strings.NewReader(tc.in))
+
+		var pe *aoc22.ParseError
+		if !errors.As(err, &pe) {
+			t.Errorf("Parse(%q) error = %v, want a ParseError", tc.in, err)
+			continue
+		}
+		if pe.Day != 21 || pe.Line != tc.line {
+			t.Errorf("Parse(%q) error = %v, want day 21 on line %d", tc.in, pe, tc.line)
+		}
+	}
+}
+
+func TestTroop_Eval(t *testing.T) {
+	cases := []struct {
+		name, in string
+		want     string // Empty if there's an error.
+	}{
+		{"fraction", "root: a / b\na: 1\nb: 3\n", "1/3"},
+		{"shared", "root: a * a\na: b - c\nb: 7\nc: 2\n", "25"},
+		{"divide by zero", "root: a / b\na: 1\nb: 0\n", ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			troop, err := Parse(strings.NewReader(tc.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := troop.Eval(Root)
+			switch {
+			case tc.want == "" && err == nil:
+				t.Errorf("Eval() = %s, want an error", got.RatString())
+			case tc.want != "" && err != nil:
+				t.Errorf("Eval() error = %v, want %s", err, tc.want)
+			case tc.want != "" && got.RatString() != tc.want:
+				t.Errorf("Eval() = %s, want %s", got.RatString(), tc.want)
+			}
+		})
+	}
+}
+
+func TestTroop_Solve(t *testing.T) {
+	cases := []struct {
+		name, in string
+		want     string // Empty if there's an error.
+	}{
+		{"left", "root: a + b\na: humn - c\nb: 10\nc: 3\nhumn: 0\n", "13"},
+		{"right", "root: b + a\na: c - humn\nb: 10\nc: 3\nhumn: 0\n", "-7"},
+		{"divided by", "root: a + b\na: c / humn\nb: 4\nc: 3\nhumn: 0\n", "3/4"},
+		{"twice", "root: a + b\na: humn * humn\nb: 4\nhumn: 0\n", ""},
+		{"times zero", "root: a + b\na: humn * c\nb: 4\nc: 0\nhumn: 0\n", ""},
+		{"divided by zero", "root: a + b\na: humn / c\nb: 4\nc: 0\nhumn: 0\n", ""},
+		{"no humn", "root: a + b\na: 1\nb: 2\n", ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			troop, err := Parse(strings.NewReader(tc.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := troop.Solve(Human)
+			switch {
+			case tc.want == "" && err == nil:
+				t.Errorf("Solve() = %s, want an error", got.RatString())
+			case tc.want != "" && err != nil:
+				t.Errorf("Solve() error = %v, want %s", err, tc.want)
+			case tc.want != "" && got.RatString() != tc.want:
+				t.Errorf("Solve() = %s, want %s", got.RatString(), tc.want)
+			}
+		})
+	}
+}
+
+func TestTroop_Equation(t *testing.T) {
+	troop, err := Parse(bytes.NewReader(aoc22.ReadTestFile(t, "testdata/small.txt")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "((4 + (2 * (humn - 3))) / 4) = ((32 - 2) * 5)"
+	if got := troop.Equation(); got != want {
+		t.Errorf("Equation() = %q, want %q", got, want)
+	}
+	if got, want := troop.Infix("ptdq"), "(5 - 3)"; got != want {
+		t.Errorf("Infix(ptdq) = %q, want %q", got, want)
+	}
+}
+
+func TestPart1(t *testing.T) {
+	aoc22.CheckAnswers(t, 21, 1)
+}
+
+func TestPart2(t *testing.T) {
+	aoc22.CheckAnswers(t, 21, 2)
+}
+
+func BenchmarkPart1(b *testing.B) {
+	data := aoc22.ReadTestFile(b, "testdata/small.txt")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Part1(context.Background(), bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPart2(b *testing.B) {
+	data := aoc22.ReadTestFile(b, "testdata/small.txt")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Part2(context.Background(), bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
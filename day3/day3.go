@@ -2,8 +2,12 @@ package day3
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"io"
+
+	"github.com/clfs/aoc22"
 )
 
 type Rucksack struct {
@@ -41,30 +45,35 @@ func (r *Rucksack) UnmarshalText(text []byte) error {
 	if len(text)%2 == 1 {
 		return fmt.Errorf("invalid rucksack: %s", text)
 	}
+	for i, c := range text {
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z') {
+			return &aoc22.ParseError{Column: i + 1, Err: fmt.Errorf("invalid item %q", c)}
+		}
+	}
 
 	bound := len(text) / 2
 	r.Left, r.Right = string(text[:bound]), string(text[bound:])
 	return nil
 }
 
-func parse(r io.Reader) []Rucksack {
+func parse(r io.Reader) ([]Rucksack, error) {
 	var result []Rucksack
 
 	scanner := bufio.NewScanner(r)
-	for scanner.Scan() {
+	for line := 1; scanner.Scan(); line++ {
 		var ruck Rucksack
 		if err := ruck.UnmarshalText(scanner.Bytes()); err != nil {
-			panic(err)
+			return nil, aoc22.NewParseError(3, line, scanner.Text(), err)
 		}
 		result = append(result, ruck)
 	}
 
-	if err := scanner.Err(); err != nil {
-		panic(err)
-	}
-	return result
+	return result, scanner.Err()
 }
 
+// errNoCommonItem is returned when rucksacks don't share an item type.
+var errNoCommonItem = errors.New("no common item")
+
 func Priority(r rune) int {
 	// Lowercase item types a through z have priorities 1 through 26.
 	// Uppercase item types A through Z have priorities 27 through 52.
@@ -79,25 +88,37 @@ func Priority(r rune) int {
 	}
 }
 
-func Part1(r io.Reader) int {
+func Part1(ctx context.Context, r io.Reader) (aoc22.Answer, error) {
+	rucks, err := parse(r)
+	if err != nil {
+		return aoc22.Answer{}, err
+	}
+
 	var sum int
-	for _, ruck := range parse(r) {
-		sum += Priority(ruck.CommonItem())
+	for i, ruck := range rucks {
+		item := ruck.CommonItem()
+		if item == 0 {
+			return aoc22.Answer{}, aoc22.NewParseError(3, i+1, ruck.Left+ruck.Right, errNoCommonItem)
+		}
+		sum += Priority(item)
 	}
-	return sum
+	return aoc22.Int(sum), nil
 }
 
-func parseGroups(r io.Reader) [][]Rucksack {
+func parseGroups(r io.Reader) ([][]Rucksack, error) {
 	var (
 		result [][]Rucksack
 		group  []Rucksack
+		line   int
 	)
 
 	scanner := bufio.NewScanner(r)
 	for scanner.Scan() {
+		line++
+
 		var ruck Rucksack
 		if err := ruck.UnmarshalText(scanner.Bytes()); err != nil {
-			panic(err)
+			return nil, aoc22.NewParseError(3, line, scanner.Text(), err)
 		}
 
 		group = append(group, ruck)
@@ -111,10 +132,14 @@ func parseGroups(r io.Reader) [][]Rucksack {
 	}
 
 	if err := scanner.Err(); err != nil {
-		panic(err)
+		return nil, err
 	}
 
-	return result
+	if len(group) > 0 {
+		return nil, aoc22.NewParseError(3, line, "", fmt.Errorf("incomplete group of %d rucksacks", len(group)))
+	}
+
+	return result, nil
 }
 
 func BadgeFor(a, b, c Rucksack) rune {
@@ -156,10 +181,27 @@ func BadgeFor(a, b, c Rucksack) rune {
 	return common
 }
 
-func Part2(r io.Reader) int {
+func Part2(ctx context.Context, r io.Reader) (aoc22.Answer, error) {
+	groups, err := parseGroups(r)
+	if err != nil {
+		return aoc22.Answer{}, err
+	}
+
 	var sum int
-	for _, group := range parseGroups(r) {
-		sum += Priority(BadgeFor(group[0], group[1], group[2]))
+	for i, group := range groups {
+		badge := BadgeFor(group[0], group[1], group[2])
+		if badge == 0 {
+			return aoc22.Answer{}, aoc22.NewParseError(3, 3*i+1, "", errNoCommonItem)
+		}
+		sum += Priority(badge)
 	}
-	return sum
+	return aoc22.Int(sum), nil
+}
+
+func init() {
+	aoc22.Register(aoc22.Puzzle{
+		Day:   3,
+		Part1: func(ctx context.Context, r io.Reader, _ aoc22.Params) (aoc22.Answer, error) { return Part1(ctx, r) },
+		Part2: func(ctx context.Context, r io.Reader, _ aoc22.Params) (aoc22.Answer, error) { return Part2(ctx, r) },
+	})
 }
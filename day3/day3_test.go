@@ -2,6 +2,9 @@ package day3
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/clfs/aoc22"
@@ -26,30 +29,59 @@ func TestPriority(t *testing.T) {
 }
 
 func TestPart1(t *testing.T) {
-	data := aoc22.ReadTestFile(t, "testdata/input.txt")
-
-	got := Part1(bytes.NewReader(data))
-	want := 8109
-
-	if got != want {
-		t.Errorf("Part1() = %d, want %d", got, want)
-	}
+	aoc22.CheckAnswers(t, 3, 1)
 }
 
 func TestPart2(t *testing.T) {
+	aoc22.CheckAnswers(t, 3, 2)
+}
+
+func TestParse_Error(t *testing.T) {
 	cases := []struct {
-		path string
-		want int
+		in        string
+		line, col int
 	}{
-		{"testdata/input.txt", 2738},
-		{"testdata/small.txt", 70},
+		{"vJrwpWtwJgWrhcsFMMfFFhFp\nabc1\n", 2, 4},
+		{"vJrwpWtwJgWrhcsFMMfFFhF\n", 1, 0},
 	}
 
-	for _, c := range cases {
-		data := aoc22.ReadTestFile(t, c.path)
-		got := Part2(bytes.NewReader(data))
-		if got != c.want {
-			t.Errorf("Part2(%q) = %d, want %d", c.path, got, c.want)
+	for _, tc := range cases {
+		_, err := Part1(context.Background(), strings.NewReader(tc.in))
+
+		var pe *aoc22.ParseError
+		if !errors.As(err, &pe) {
+			t.Errorf("Part1(%q) error = %v, want a ParseError", tc.in, err)
+			continue
+		}
+		if pe.Line != tc.line || pe.Column != tc.col {
+			t.Errorf("Part1(%q) error at %d:%d, want %d:%d", tc.in, pe.Line, pe.Column, tc.line, tc.col)
+		}
+	}
+
+	// Part 2 needs complete groups of three.
+	if _, err := Part2(context.Background(), strings.NewReader("ab\ncb\n")); err == nil {
+		t.Error("Part2() with an incomplete group succeeded")
+	}
+}
+
+func BenchmarkPart1(b *testing.B) {
+	data := aoc22.ReadTestFile(b, "testdata/input.txt")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Part1(context.Background(), bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPart2(b *testing.B) {
+	data := aoc22.ReadTestFile(b, "testdata/input.txt")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Part2(context.Background(), bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
 		}
 	}
 }
@@ -1,84 +1,97 @@
 package day4
 
 import (
-	"bufio"
-	"fmt"
+	"context"
+	"errors"
 	"io"
 	"regexp"
-	"strconv"
+
+	"github.com/clfs/aoc22"
+	"github.com/clfs/aoc22/interval"
 )
 
 type Pair struct {
-	LeftLow   int
-	LeftHigh  int
-	RightLow  int
-	RightHigh int
+	LeftLow   int `aoc:"leftlow"`
+	LeftHigh  int `aoc:"lefthigh"`
+	RightLow  int `aoc:"rightlow"`
+	RightHigh int `aoc:"righthigh"`
 }
 
-var pairRegexp = regexp.MustCompile(`^(\d+)-(\d+),(\d+)-(\d+)$`)
+var pairRegexp = regexp.MustCompile(`^(?P<leftlow>\d+)-(?P<lefthigh>\d+),(?P<rightlow>\d+)-(?P<righthigh>\d+)$`)
 
 func (p *Pair) UnmarshalText(text []byte) error {
-	matches := pairRegexp.FindStringSubmatch(string(text))
-	if matches == nil {
-		return fmt.Errorf("invalid pair: %s", text)
-	}
-	p.LeftLow, _ = strconv.Atoi(matches[1])
-	p.LeftHigh, _ = strconv.Atoi(matches[2])
-	p.RightLow, _ = strconv.Atoi(matches[3])
-	p.RightHigh, _ = strconv.Atoi(matches[4])
-	return nil
+	return aoc22.Decode(pairRegexp, string(text), p)
+}
+
+// Left returns the left elf's range of sections.
+func (p *Pair) Left() interval.Range {
+	return interval.Range{Low: p.LeftLow, High: p.LeftHigh}
+}
+
+// Right returns the right elf's range of sections.
+func (p *Pair) Right() interval.Range {
+	return interval.Range{Low: p.RightLow, High: p.RightHigh}
 }
 
 // Redundant returns true if one of the ranges is contained in the other.
 func (p *Pair) Redundant() bool {
-	return (p.LeftLow >= p.RightLow && p.LeftHigh <= p.RightHigh) ||
-		(p.RightLow >= p.LeftLow && p.RightHigh <= p.LeftHigh)
+	return p.Left().ContainsRange(p.Right()) || p.Right().ContainsRange(p.Left())
 }
 
 // AnyOverlap returns true if the left and right ranges overlap at all.
 func (p *Pair) AnyOverlap() bool {
-	return (p.LeftLow <= p.RightLow && p.LeftHigh >= p.RightLow) ||
-		(p.RightLow <= p.LeftLow && p.RightHigh >= p.LeftLow)
+	return p.Left().Overlaps(p.Right())
 }
 
-func Part1(r io.Reader) int {
-	var count int
-
-	scanner := bufio.NewScanner(r)
-	for scanner.Scan() {
-		var p Pair
-		if err := p.UnmarshalText(scanner.Bytes()); err != nil {
-			panic(err)
+// Parse parses a list of pairs, one per line.
+func Parse(r io.Reader) ([]Pair, error) {
+	pairs, err := aoc22.DecodeLines[Pair](r, pairRegexp)
+	if err != nil {
+		var pe *aoc22.ParseError
+		if errors.As(err, &pe) {
+			pe.Day = 4
 		}
+		return nil, err
+	}
+	return pairs, nil
+}
+
+func Part1(ctx context.Context, r io.Reader) (aoc22.Answer, error) {
+	pairs, err := Parse(r)
+	if err != nil {
+		return aoc22.Answer{}, err
+	}
+
+	var count int
+	for _, p := range pairs {
 		if p.Redundant() {
 			count++
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		panic(err)
-	}
-
-	return count
+	return aoc22.Int(count), nil
 }
 
-func Part2(r io.Reader) int {
-	var count int
+func Part2(ctx context.Context, r io.Reader) (aoc22.Answer, error) {
+	pairs, err := Parse(r)
+	if err != nil {
+		return aoc22.Answer{}, err
+	}
 
-	scanner := bufio.NewScanner(r)
-	for scanner.Scan() {
-		var p Pair
-		if err := p.UnmarshalText(scanner.Bytes()); err != nil {
-			panic(err)
-		}
+	var count int
+	for _, p := range pairs {
 		if p.AnyOverlap() {
 			count++
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		panic(err)
-	}
+	return aoc22.Int(count), nil
+}
 
-	return count
+func init() {
+	aoc22.Register(aoc22.Puzzle{
+		Day:   4,
+		Part1: func(ctx context.Context, r io.Reader, _ aoc22.Params) (aoc22.Answer, error) { return Part1(ctx, r) },
+		Part2: func(ctx context.Context, r io.Reader, _ aoc22.Params) (aoc22.Answer, error) { return Part2(ctx, r) },
+	})
 }
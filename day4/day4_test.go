@@ -2,43 +2,94 @@ package day4
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"math/rand"
+	"reflect"
+	"strings"
 	"testing"
+	"testing/quick"
 
 	"github.com/clfs/aoc22"
 )
 
-func TestPart1(t *testing.T) {
-	cases := []struct {
-		name string
-		want int
-	}{
-		{"testdata/input.txt", 582},
-		{"testdata/small.txt", 2},
-	}
+// randPair is a random pair for property tests, with small sections so that
+// the ranges often overlap or contain each other.
+type randPair struct{ Pair }
 
-	for _, tc := range cases {
-		got := Part1(bytes.NewReader(aoc22.ReadTestFile(t, tc.name)))
+func (randPair) Generate(rng *rand.Rand, size int) reflect.Value {
+	var p Pair
+	p.LeftLow = 1 + rng.Intn(10)
+	p.LeftHigh = p.LeftLow + rng.Intn(10)
+	p.RightLow = 1 + rng.Intn(10)
+	p.RightHigh = p.RightLow + rng.Intn(10)
+	return reflect.ValueOf(randPair{p})
+}
 
-		if got != tc.want {
-			t.Errorf("Part1(%q) = %d, want %d", tc.name, got, tc.want)
+func TestPair_Symmetric(t *testing.T) {
+	f := func(p randPair) bool {
+		swapped := Pair{
+			LeftLow:   p.RightLow,
+			LeftHigh:  p.RightHigh,
+			RightLow:  p.LeftLow,
+			RightHigh: p.LeftHigh,
 		}
+		return p.Redundant() == swapped.Redundant() && p.AnyOverlap() == swapped.AnyOverlap()
+	}
+	if err := quick.Check(f, &quick.Config{MaxCount: 1000}); err != nil {
+		t.Error(err)
 	}
 }
 
+func TestPart1(t *testing.T) {
+	aoc22.CheckAnswers(t, 4, 1)
+}
+
 func TestPart2(t *testing.T) {
+	aoc22.CheckAnswers(t, 4, 2)
+}
+
+func TestParse_Error(t *testing.T) {
 	cases := []struct {
-		name string
-		want int
+		in        string
+		line, col int
 	}{
-		{"testdata/input.txt", 893},
-		{"testdata/small.txt", 4},
+		{"2-4,6-8\n2-3,4\n", 2, 0},
+		{"2-4,6-8\n2-3,4-99999999999999999999\n", 2, 7},
 	}
 
 	for _, tc := range cases {
-		got := Part2(bytes.NewReader(aoc22.ReadTestFile(t, tc.name)))
+		_, err := Parse(strings.NewReader(tc.in))
+
+		var pe *aoc22.ParseError
+		if !errors.As(err, &pe) {
+			t.Errorf("Parse(%q) error = %v, want a ParseError", tc.in, err)
+			continue
+		}
+		if pe.Day != 4 || pe.Line != tc.line || pe.Column != tc.col {
+			t.Errorf("Parse(%q) error = %v, want day 4 at %d:%d", tc.in, pe, tc.line, tc.col)
+		}
+	}
+}
+
+func BenchmarkPart1(b *testing.B) {
+	data := aoc22.ReadTestFile(b, "testdata/input.txt")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Part1(context.Background(), bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
 
-		if got != tc.want {
-			t.Errorf("Part1(%q) = %d, want %d", tc.name, got, tc.want)
+func BenchmarkPart2(b *testing.B) {
+	data := aoc22.ReadTestFile(b, "testdata/input.txt")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Part2(context.Background(), bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
 		}
 	}
 }
@@ -2,50 +2,29 @@ package day5
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"regexp"
-	"strconv"
 	"strings"
+
+	"github.com/clfs/aoc22"
 )
 
 type Move struct {
-	Count    int // How many crates to move
-	Src, Dst int // The source and destination stacks; one-indexed
+	Count int `aoc:"count"` // How many crates to move
+	Src   int `aoc:"src"`   // The source stack; one-indexed
+	Dst   int `aoc:"dst"`   // The destination stack; one-indexed
 }
 
 // "move 10 from 4 to 3"
 // should return
 // Move{Count: 10, Src: 4, Dst: 3}
-var moveRegexp = regexp.MustCompile(`move (\d+) from (\d+) to (\d+)`)
+var moveRegexp = regexp.MustCompile(`^move (?P<count>\d+) from (?P<src>\d+) to (?P<dst>\d+)$`)
 
 func (m *Move) UnmarshalText(text []byte) error {
-	// use moveRegexp to parse the text
-	matches := moveRegexp.FindStringSubmatch(string(text))
-	if matches == nil {
-		return fmt.Errorf("invalid move: %q", text)
-	}
-
-	count, err := strconv.Atoi(matches[1])
-	if err != nil {
-		return fmt.Errorf("invalid count: %q", matches[1])
-	}
-
-	src, err := strconv.Atoi(matches[2])
-	if err != nil {
-		return fmt.Errorf("invalid source: %q", matches[2])
-	}
-
-	dst, err := strconv.Atoi(matches[3])
-	if err != nil {
-		return fmt.Errorf("invalid destination: %q", matches[3])
-	}
-
-	m.Count = count
-	m.Src = src
-	m.Dst = dst
-
-	return nil
+	return aoc22.Decode(moveRegexp, string(text), m)
 }
 
 func Rearrange(crates [][]rune, moves []Move) [][]rune {
@@ -66,7 +45,7 @@ func RearrangeMultipleAtOnce(crates [][]rune, moves []Move) [][]rune {
 	return crates
 }
 
-func parse(r io.Reader) ([][]rune, []Move) {
+func parse(r io.Reader) ([][]rune, []Move, error) {
 	// First, read the stacks of crates.
 	//
 	//     [D]
@@ -77,15 +56,25 @@ func parse(r io.Reader) ([][]rune, []Move) {
 
 	data, err := io.ReadAll(r)
 	if err != nil {
-		panic(err)
+		return nil, nil, err
 	}
 
-	fields := strings.Split(string(data), "\n\n")
+	fields := strings.SplitN(string(data), "\n\n", 2)
+	if len(fields) != 2 {
+		return nil, nil, &aoc22.ParseError{Day: 5, Err: errors.New("no blank line between crates and moves")}
+	}
 
 	crates := parseCrates(fields[0])
-	moves := parseMoves(fields[1])
 
-	return crates, moves
+	// The moves start after the crate lines and the blank line.
+	firstLine := strings.Count(fields[0], "\n") + 3
+
+	moves, err := parseMoves(fields[1], firstLine, len(crates))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return crates, moves, nil
 }
 
 func parseCrates(s string) [][]rune {
@@ -117,10 +106,6 @@ func parseCrates(s string) [][]rune {
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		panic(err)
-	}
-
 	// reverse the order of the stacks
 
 	for i := 0; i < len(crates); i++ {
@@ -138,21 +123,31 @@ func reverse(sl []rune) []rune {
 	return sl
 }
 
-func parseMoves(s string) []Move {
+// parseMoves parses moves between nStacks stacks, one per line. The first
+// move is on line firstLine of the input.
+func parseMoves(s string, firstLine, nStacks int) ([]Move, error) {
 	scanner := bufio.NewScanner(strings.NewReader(s))
 	moves := make([]Move, 0)
-	for scanner.Scan() {
+	for line := firstLine; scanner.Scan(); line++ {
 		var m Move
 		if err := m.UnmarshalText(scanner.Bytes()); err != nil {
-			panic(err)
+			return nil, aoc22.NewParseError(5, line, scanner.Text(), err)
+		}
+		for _, stack := range []int{m.Src, m.Dst} {
+			if stack < 1 || stack > nStacks {
+				return nil, aoc22.NewParseError(5, line, scanner.Text(), fmt.Errorf("no stack %d", stack))
+			}
 		}
 		moves = append(moves, m)
 	}
-	return moves
+	return moves, scanner.Err()
 }
 
-func Part1(r io.Reader) string {
-	crates, moves := parse(r)
+func Part1(ctx context.Context, r io.Reader) (aoc22.Answer, error) {
+	crates, moves, err := parse(r)
+	if err != nil {
+		return aoc22.Answer{}, err
+	}
 	crates = Rearrange(crates, moves)
 
 	tops := make([]rune, 0)
@@ -160,11 +155,14 @@ func Part1(r io.Reader) string {
 		tops = append(tops, stack[len(stack)-1])
 	}
 
-	return string(tops)
+	return aoc22.Text(string(tops)), nil
 }
 
-func Part2(r io.Reader) string {
-	crates, moves := parse(r)
+func Part2(ctx context.Context, r io.Reader) (aoc22.Answer, error) {
+	crates, moves, err := parse(r)
+	if err != nil {
+		return aoc22.Answer{}, err
+	}
 	crates = RearrangeMultipleAtOnce(crates, moves)
 
 	tops := make([]rune, 0)
@@ -172,5 +170,13 @@ func Part2(r io.Reader) string {
 		tops = append(tops, stack[len(stack)-1])
 	}
 
-	return string(tops)
+	return aoc22.Text(string(tops)), nil
+}
+
+func init() {
+	aoc22.Register(aoc22.Puzzle{
+		Day:   5,
+		Part1: func(ctx context.Context, r io.Reader, _ aoc22.Params) (aoc22.Answer, error) { return Part1(ctx, r) },
+		Part2: func(ctx context.Context, r io.Reader, _ aoc22.Params) (aoc22.Answer, error) { return Part2(ctx, r) },
+	})
 }
@@ -2,43 +2,133 @@ package day5
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"math/rand"
+	"reflect"
+	"strings"
 	"testing"
+	"testing/quick"
 
 	"github.com/clfs/aoc22"
 )
 
-func TestPart1(t *testing.T) {
-	cases := []struct {
-		path string
-		want string
-	}{
-		{"testdata/small.txt", "CMZ"},
-		{"testdata/input.txt", "SHQWSRBDL"},
+// randProcedure is a random rearrangement for property tests. Every move
+// takes at most as many crates as its source stack holds at that point.
+type randProcedure struct {
+	crates [][]rune
+	moves  []Move
+}
+
+func (randProcedure) Generate(rng *rand.Rand, size int) reflect.Value {
+	var p randProcedure
+	p.crates = make([][]rune, 2+rng.Intn(8))
+	heights := make([]int, len(p.crates))
+	for i := range p.crates {
+		for j := rng.Intn(9); j > 0; j-- {
+			p.crates[i] = append(p.crates[i], rune('A'+rng.Intn(26)))
+		}
+		heights[i] = len(p.crates[i])
 	}
 
-	for _, c := range cases {
-		data := aoc22.ReadTestFile(t, c.path)
-		got := Part1(bytes.NewReader(data))
-		if got != c.want {
-			t.Errorf("Part1(%q) == %q, want %q", c.path, got, c.want)
+	for i := rng.Intn(20); i > 0; i-- {
+		src, dst := rng.Intn(len(heights)), rng.Intn(len(heights)-1)
+		if dst >= src {
+			dst++
+		}
+		if heights[src] == 0 {
+			continue
 		}
+		count := 1 + rng.Intn(heights[src])
+		heights[src] -= count
+		heights[dst] += count
+		p.moves = append(p.moves, Move{Count: count, Src: src + 1, Dst: dst + 1})
 	}
+	return reflect.ValueOf(p)
+}
+
+func countCrates(crates [][]rune) int {
+	var n int
+	for _, stack := range crates {
+		n += len(stack)
+	}
+	return n
+}
+
+func cloneCrates(crates [][]rune) [][]rune {
+	clone := make([][]rune, len(crates))
+	for i, stack := range crates {
+		clone[i] = append([]rune(nil), stack...)
+	}
+	return clone
+}
+
+func TestRearrange_KeepsCrates(t *testing.T) {
+	for name, rearrange := range map[string]func([][]rune, []Move) [][]rune{
+		"Rearrange":               Rearrange,
+		"RearrangeMultipleAtOnce": RearrangeMultipleAtOnce,
+	} {
+		f := func(p randProcedure) bool {
+			want := countCrates(p.crates)
+			return countCrates(rearrange(cloneCrates(p.crates), p.moves)) == want
+		}
+		if err := quick.Check(f, &quick.Config{MaxCount: 1000}); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestPart1(t *testing.T) {
+	aoc22.CheckAnswers(t, 5, 1)
 }
 
 func TestPart2(t *testing.T) {
+	aoc22.CheckAnswers(t, 5, 2)
+}
+
+func TestParse_Error(t *testing.T) {
 	cases := []struct {
-		path string
-		want string
+		name string
+		in   string
+		line int
 	}{
-		{"testdata/small.txt", "MCD"},
-		{"testdata/input.txt", "CDTQZHBRS"},
+		{"no blank line", "    [D]\n[N] [C]\n 1   2\n", 0},
+		{"bad move", "[N] [C]\n 1   2\n\nmove 1 from 1 to 2\nmove x from 2 to 1\n", 5},
+		{"missing stack", "[N] [C]\n 1   2\n\nmove 1 from 3 to 2\n", 4},
+	}
+
+	for _, tc := range cases {
+		_, err := Part1(context.Background(), strings.NewReader(tc.in))
+
+		var pe *aoc22.ParseError
+		if !errors.As(err, &pe) {
+			t.Errorf("%s: error = %v, want a ParseError", tc.name, err)
+			continue
+		}
+		if pe.Day != 5 || pe.Line != tc.line {
+			t.Errorf("%s: error = %v, want day 5, line %d", tc.name, pe, tc.line)
+		}
+	}
+}
+
+func BenchmarkPart1(b *testing.B) {
+	data := aoc22.ReadTestFile(b, "testdata/input.txt")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Part1(context.Background(), bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
 	}
+}
 
-	for _, c := range cases {
-		data := aoc22.ReadTestFile(t, c.path)
-		got := Part2(bytes.NewReader(data))
-		if got != c.want {
-			t.Errorf("Part1(%q) == %q, want %q", c.path, got, c.want)
+func BenchmarkPart2(b *testing.B) {
+	data := aoc22.ReadTestFile(b, "testdata/input.txt")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Part2(context.Background(), bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
 		}
 	}
 }
@@ -1,23 +1,33 @@
 package day6
 
-func Part1(s string) int {
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/clfs/aoc22"
+)
+
+var errNoMarker = errors.New("no marker found")
+
+func Part1(ctx context.Context, s string) (aoc22.Answer, error) {
 	for i := 0; i < len(s)-4; i++ {
 		window := s[i : i+4]
 		if isSOP(window) {
-			return i + 4 // the answer's one-indexed
+			return aoc22.Int(i + 4), nil // the answer's one-indexed
 		}
 	}
-	return -1
+	return aoc22.Answer{}, errNoMarker
 }
 
-func Part2(s string) int {
+func Part2(ctx context.Context, s string) (aoc22.Answer, error) {
 	for i := 0; i < len(s)-14; i++ {
 		window := s[i : i+14]
 		if isSOP(window) {
-			return i + 14 // the answer's one-indexed
+			return aoc22.Int(i + 14), nil // the answer's one-indexed
 		}
 	}
-	return -1
+	return aoc22.Answer{}, errNoMarker
 }
 
 func isSOP(s string) bool {
@@ -30,3 +40,24 @@ func isSOP(s string) bool {
 	}
 	return true
 }
+
+func init() {
+	aoc22.Register(aoc22.Puzzle{
+		Day: 6,
+		Part1: func(ctx context.Context, r io.Reader, _ aoc22.Params) (aoc22.Answer, error) {
+			return solve(ctx, r, Part1)
+		},
+		Part2: func(ctx context.Context, r io.Reader, _ aoc22.Params) (aoc22.Answer, error) {
+			return solve(ctx, r, Part2)
+		},
+	})
+}
+
+// solve adapts a part to read its datastream from r.
+func solve(ctx context.Context, r io.Reader, part func(context.Context, string) (aoc22.Answer, error)) (aoc22.Answer, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return aoc22.Answer{}, err
+	}
+	return part(ctx, string(data))
+}
@@ -1,6 +1,7 @@
 package day6
 
 import (
+	"context"
 	"testing"
 
 	"github.com/clfs/aoc22"
@@ -19,22 +20,18 @@ func TestPart1_Examples(t *testing.T) {
 	}
 
 	for _, c := range cases {
-		got := Part1(c.in)
-		if got != c.want {
-			t.Errorf("Part1(%q) == %d, want %d", c.in, got, c.want)
+		got, err := Part1(context.Background(), c.in)
+		if err != nil {
+			t.Errorf("Part1(%q) error: %v", c.in, err)
+		}
+		if got != aoc22.Int(c.want) {
+			t.Errorf("Part1(%q) == %v, want %d", c.in, got, c.want)
 		}
 	}
 }
 
 func TestPart1(t *testing.T) {
-	data := aoc22.ReadTestFile(t, "testdata/input.txt")
-
-	got := Part1(string(data))
-	want := 1544
-
-	if got != want {
-		t.Errorf("Part1() == %d, want %d", got, want)
-	}
+	aoc22.CheckAnswers(t, 6, 1)
 }
 
 func TestPart2_Examples(t *testing.T) {
@@ -50,20 +47,38 @@ func TestPart2_Examples(t *testing.T) {
 	}
 
 	for _, c := range cases {
-		got := Part2(c.in)
-		if got != c.want {
-			t.Errorf("Part2(%q) == %d, want %d", c.in, got, c.want)
+		got, err := Part2(context.Background(), c.in)
+		if err != nil {
+			t.Errorf("Part2(%q) error: %v", c.in, err)
+		}
+		if got != aoc22.Int(c.want) {
+			t.Errorf("Part2(%q) == %v, want %d", c.in, got, c.want)
 		}
 	}
 }
 
 func TestPart2(t *testing.T) {
-	data := aoc22.ReadTestFile(t, "testdata/input.txt")
+	aoc22.CheckAnswers(t, 6, 2)
+}
 
-	got := Part2(string(data))
-	want := 2145
+func BenchmarkPart1(b *testing.B) {
+	data := aoc22.ReadTestFile(b, "testdata/input.txt")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Part1(context.Background(), string(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
 
-	if got != want {
-		t.Errorf("Part2() == %d, want %d", got, want)
+func BenchmarkPart2(b *testing.B) {
+	data := aoc22.ReadTestFile(b, "testdata/input.txt")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Part2(context.Background(), string(data)); err != nil {
+			b.Fatal(err)
+		}
 	}
 }
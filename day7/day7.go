@@ -2,10 +2,15 @@ package day7
 
 import (
 	"bufio"
+	"context"
+	"fmt"
 	"io"
 	"math"
+	"math/rand"
 	"strconv"
 	"strings"
+
+	"github.com/clfs/aoc22"
 )
 
 func Parse(r io.Reader) (map[string]int64, error) {
@@ -39,10 +44,10 @@ func Parse(r io.Reader) (map[string]int64, error) {
 	return result, scanner.Err()
 }
 
-func Part1(r io.Reader) (int64, error) {
+func Part1(ctx context.Context, r io.Reader) (aoc22.Answer, error) {
 	filesystem, err := Parse(r)
 	if err != nil {
-		return 0, err
+		return aoc22.Answer{}, err
 	}
 
 	dirSizes := make(map[string]int64)
@@ -62,7 +67,7 @@ func Part1(r io.Reader) (int64, error) {
 		}
 	}
 
-	return result, nil
+	return aoc22.Int64(result), nil
 }
 
 func DirSize(name string, filesystem map[string]int64) int64 {
@@ -84,10 +89,10 @@ const (
 	UpdateSpace = 30000000
 )
 
-func Part2(r io.Reader) (int64, error) {
+func Part2(ctx context.Context, r io.Reader) (aoc22.Answer, error) {
 	filesystem, err := Parse(r)
 	if err != nil {
-		return 0, err
+		return aoc22.Answer{}, err
 	}
 
 	dirSizes := make(map[string]int64)
@@ -114,5 +119,150 @@ func Part2(r io.Reader) (int64, error) {
 		}
 	}
 
-	return bestSize, nil
+	return aoc22.Int64(bestSize), nil
+}
+
+// A genDir is a directory in a generated file system.
+type genDir struct {
+	dirs  []*genDir
+	files []genFile
+	names map[string]bool // The names of the entries, dirs and files alike.
+	name  string
+}
+
+type genFile struct {
+	name string
+	size int64
+}
+
+// newName returns a random name that isn't in use in d yet, and reserves it.
+func (d *genDir) newName(rng *rand.Rand, ext bool) string {
+	if d.names == nil {
+		d.names = make(map[string]bool)
+	}
+	for {
+		name := randWord(rng, 1+rng.Intn(8))
+		if ext {
+			name += "." + randWord(rng, 3)
+		}
+		if !d.names[name] {
+			d.names[name] = true
+			return name
+		}
+	}
+}
+
+func randWord(rng *rand.Rand, n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte('a' + rng.Intn(26))
+	}
+	return string(b)
+}
+
+// write writes the commands that list d and then each directory under it.
+func (d *genDir) write(w io.Writer, rng *rand.Rand) {
+	fmt.Fprintln(w, "$ ls")
+
+	var entries []string
+	for _, sub := range d.dirs {
+		entries = append(entries, "dir "+sub.name)
+	}
+	for _, f := range d.files {
+		entries = append(entries, fmt.Sprintf("%d %s", f.size, f.name))
+	}
+	rng.Shuffle(len(entries), func(i, j int) { entries[i], entries[j] = entries[j], entries[i] })
+	for _, e := range entries {
+		fmt.Fprintln(w, e)
+	}
+
+	for _, sub := range d.dirs {
+		fmt.Fprintf(w, "$ cd %s\n", sub.name)
+		sub.write(w, rng)
+		fmt.Fprintln(w, "$ cd ..")
+	}
+}
+
+// size returns the total size of the files under d, and adds the size of
+// each directory under d, and d itself, to sizes.
+func (d *genDir) size(sizes *[]int64) int64 {
+	var total int64
+	for _, f := range d.files {
+		total += f.size
+	}
+	for _, sub := range d.dirs {
+		total += sub.size(sizes)
+	}
+	*sizes = append(*sizes, total)
+	return total
+}
+
+// Generate writes a terminal session that explores a random file system with
+// size directories besides the root. Both answers are known.
+func Generate(w io.Writer, rng *rand.Rand, size int) (aoc22.Generated, error) {
+	if size < 0 {
+		return aoc22.Generated{}, fmt.Errorf("invalid size %d", size)
+	}
+	if size == 0 {
+		size = 165
+	}
+
+	// Hanging each directory off a random earlier one makes the tree
+	// about as deep as a real one.
+	root := &genDir{name: "/"}
+	dirs := []*genDir{root}
+	for i := 0; i < size; i++ {
+		parent := dirs[rng.Intn(len(dirs))]
+		d := &genDir{name: parent.newName(rng, false)}
+		parent.dirs = append(parent.dirs, d)
+		dirs = append(dirs, d)
+	}
+
+	// Spread the files so that the disk is about as full as a real one,
+	// and an update needs a directory deleted.
+	const used = DiskSpace - UpdateSpace/2
+	nFiles := 2 * len(dirs)
+	maxSize := 2 * int64(used) / int64(nFiles)
+	for i := 0; i < nFiles; i++ {
+		d := dirs[rng.Intn(len(dirs))]
+		d.files = append(d.files, genFile{name: d.newName(rng, rng.Intn(2) == 0), size: 1 + rng.Int63n(maxSize)})
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "$ cd /")
+	root.write(bw, rng)
+	if err := bw.Flush(); err != nil {
+		return aoc22.Generated{}, err
+	}
+
+	var sizes []int64
+	total := root.size(&sizes)
+
+	var (
+		small int64
+		best  int64 = math.MaxInt64
+	)
+	for _, n := range sizes {
+		if n <= 100000 {
+			small += n
+		}
+		if DiskSpace-total+n >= UpdateSpace {
+			best = min(best, n)
+		}
+	}
+
+	g := aoc22.Generated{Answers: [2]aoc22.Answer{aoc22.Int64(small)}}
+	if best != math.MaxInt64 {
+		g.Answers[1] = aoc22.Int64(best)
+	}
+	return g, nil
+}
+
+func init() {
+	aoc22.Register(aoc22.Puzzle{
+		Day:      7,
+		Part1:    func(ctx context.Context, r io.Reader, _ aoc22.Params) (aoc22.Answer, error) { return Part1(ctx, r) },
+		Part2:    func(ctx context.Context, r io.Reader, _ aoc22.Params) (aoc22.Answer, error) { return Part2(ctx, r) },
+		Generate: Generate,
+	})
 }
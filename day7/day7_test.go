@@ -2,6 +2,9 @@ package day7
 
 import (
 	"bytes"
+	"context"
+	"io"
+	"math/rand"
 	"os"
 	"testing"
 
@@ -59,43 +62,55 @@ func TestParse(t *testing.T) {
 }
 
 func TestPart1(t *testing.T) {
-	cases := []struct {
-		name string
-		want int64
-	}{
-		{"testdata/input.txt", 1778099},
-		{"testdata/small.txt", 95437},
-	}
+	aoc22.CheckAnswers(t, 7, 1)
+}
+
+func TestPart2(t *testing.T) {
+	aoc22.CheckAnswers(t, 7, 2)
+}
 
-	for _, c := range cases {
-		data := aoc22.ReadTestFile(t, c.name)
-		got, err := Part1(bytes.NewReader(data))
+func TestGenerate(t *testing.T) {
+	for seed := int64(1); seed <= 10; seed++ {
+		var buf bytes.Buffer
+		g, err := Generate(&buf, rand.New(rand.NewSource(seed)), int(seed)*20)
 		if err != nil {
-			t.Errorf("%q: %v", c.name, err)
+			t.Fatalf("seed %d: %v", seed, err)
 		}
-		if got != c.want {
-			t.Errorf("%q: got %d, want %d", c.name, got, c.want)
+
+		for n, solve := range []func(context.Context, io.Reader) (aoc22.Answer, error){Part1, Part2} {
+			want, ok := g.Answer(n + 1)
+			if !ok {
+				continue
+			}
+			got, err := solve(context.Background(), bytes.NewReader(buf.Bytes()))
+			if err != nil {
+				t.Fatalf("seed %d part %d: %v", seed, n+1, err)
+			}
+			if got != want {
+				t.Errorf("seed %d part %d: got %v, want %v", seed, n+1, got, want)
+			}
 		}
 	}
 }
 
-func TestPart2(t *testing.T) {
-	cases := []struct {
-		name string
-		want int64
-	}{
-		{"testdata/input.txt", 1623571},
-		{"testdata/small.txt", 24933642},
+func BenchmarkPart1(b *testing.B) {
+	data := aoc22.ReadTestFile(b, "testdata/input.txt")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Part1(context.Background(), bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
 	}
+}
 
-	for _, c := range cases {
-		data := aoc22.ReadTestFile(t, c.name)
-		got, err := Part2(bytes.NewReader(data))
-		if err != nil {
-			t.Errorf("%q: %v", c.name, err)
-		}
-		if got != c.want {
-			t.Errorf("%q: got %d, want %d", c.name, got, c.want)
+func BenchmarkPart2(b *testing.B) {
+	data := aoc22.ReadTestFile(b, "testdata/input.txt")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Part2(context.Background(), bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
 		}
 	}
 }
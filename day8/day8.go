@@ -1,11 +1,17 @@
 package day8
 
 import (
-	"bufio"
+	"context"
+	"errors"
+	"fmt"
 	"io"
+
+	"github.com/clfs/aoc22"
+	"github.com/clfs/aoc22/geom"
+	"github.com/clfs/aoc22/grid"
 )
 
-func Part1(f *Forest) (int, error) {
+func Part1(ctx context.Context, f *Forest) (aoc22.Answer, error) {
 	var result int
 
 	for r := 0; r < f.SideLen(); r++ {
@@ -16,30 +22,37 @@ func Part1(f *Forest) (int, error) {
 		}
 	}
 
-	return result, nil
+	return aoc22.Int(result), nil
 }
 
 type Forest struct {
-	Grid [][]int
+	Grid *grid.Grid[int]
 }
 
 func NewForest(r io.Reader) (*Forest, error) {
-	var grid [][]int
-
-	s := bufio.NewScanner(r)
-	for s.Scan() {
-		var row []int
-		for _, rn := range s.Text() {
-			row = append(row, int(rn-'0'))
+	g, err := grid.Parse(r, func(_ geom.Point, rn rune) (int, error) {
+		if rn < '0' || rn > '9' {
+			return 0, fmt.Errorf("invalid tree height %q", rn)
 		}
-		grid = append(grid, row)
+		return int(rn - '0'), nil
+	})
+	if err != nil {
+		var pe *aoc22.ParseError
+		if errors.As(err, &pe) {
+			pe.Day = 8
+		}
+		return nil, err
+	}
+
+	if g.Width() != g.Height() {
+		return nil, fmt.Errorf("forest is %dx%d, want a square", g.Width(), g.Height())
 	}
 
-	return &Forest{Grid: grid}, nil
+	return &Forest{Grid: g}, nil
 }
 
 func (f Forest) SideLen() int {
-	return len(f.Grid)
+	return f.Grid.Height()
 }
 
 func (f Forest) IsEdge(r, c int) bool {
@@ -71,21 +84,14 @@ func (f Forest) IsVisible(r, c int) bool {
 // IsVisibleInDirection returns true if the tree at (r, c) has line-of-sight
 // to the edge of the forest in the direction (dr, dc).
 func (f Forest) IsVisibleInDirection(r, c, dr, dc int) bool {
-	height := f.Grid[r][c]
-
-	for {
-		if f.IsEdge(r, c) {
-			return true
-		}
+	height := f.Grid.At(geom.Pt(c, r))
 
-		r += dr
-		c += dc
-
-		target := f.Grid[r][c]
+	for _, target := range f.Grid.Ray(geom.Pt(c, r), geom.Pt(dc, dr)) {
 		if target >= height {
 			return false
 		}
 	}
+	return true
 }
 
 // ScenicScore is the product of the viewing distances in each cardinal direction.
@@ -106,26 +112,18 @@ func (f Forest) ScenicScore(r, c int) int {
 // the direction (dr, dc). Disregard trees higher than the starting tree, since
 // we can't look to the sky.
 func (f Forest) ViewingDistance(r, c, dr, dc int) int {
-	height := f.Grid[r][c]
-	distance := 0
-
-	for {
-		if f.IsEdge(r, c) {
-			return distance
-		}
-
-		r += dr
-		c += dc
-		distance++
+	height := f.Grid.At(geom.Pt(c, r))
 
-		target := f.Grid[r][c]
+	ray := f.Grid.Ray(geom.Pt(c, r), geom.Pt(dc, dr))
+	for i, target := range ray {
 		if target >= height {
-			return distance
+			return i + 1
 		}
 	}
+	return len(ray)
 }
 
-func Part2(f *Forest) int {
+func Part2(ctx context.Context, f *Forest) (aoc22.Answer, error) {
 	var best int
 	for r := 0; r < f.SideLen(); r++ {
 		for c := 0; c < f.SideLen(); c++ {
@@ -134,5 +132,25 @@ func Part2(f *Forest) int {
 			}
 		}
 	}
-	return best
+	return aoc22.Int(best), nil
+}
+
+func init() {
+	aoc22.Register(aoc22.Puzzle{
+		Day: 8,
+		Part1: func(ctx context.Context, r io.Reader, _ aoc22.Params) (aoc22.Answer, error) {
+			f, err := NewForest(r)
+			if err != nil {
+				return aoc22.Answer{}, err
+			}
+			return Part1(ctx, f)
+		},
+		Part2: func(ctx context.Context, r io.Reader, _ aoc22.Params) (aoc22.Answer, error) {
+			f, err := NewForest(r)
+			if err != nil {
+				return aoc22.Answer{}, err
+			}
+			return Part2(ctx, f)
+		},
+	})
 }
@@ -1,9 +1,12 @@
 package day8
 
 import (
+	"bytes"
+	"context"
 	"os"
 	"testing"
 
+	"github.com/clfs/aoc22"
 	"github.com/google/go-cmp/cmp"
 )
 
@@ -19,15 +22,20 @@ func TestNewForest(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	want := &Forest{Grid: [][]int{
+	want := [][]int{
 		{3, 0, 3, 7, 3},
 		{2, 5, 5, 1, 2},
 		{6, 5, 3, 3, 2},
 		{3, 3, 5, 4, 9},
 		{3, 5, 3, 9, 0},
-	}}
+	}
+
+	var rows [][]int
+	for y := 0; y < got.Grid.Height(); y++ {
+		rows = append(rows, got.Grid.Row(y))
+	}
 
-	if diff := cmp.Diff(want, got); diff != "" {
+	if diff := cmp.Diff(want, rows); diff != "" {
 		t.Errorf("NewForest() mismatch (-want +got):\n%s", diff)
 	}
 }
@@ -76,25 +84,7 @@ func TestForest_IsVisibleInDirection(t *testing.T) {
 }
 
 func TestPart1(t *testing.T) {
-	cases := []struct {
-		name string
-		want int
-	}{
-		{"testdata/small.txt", 21},
-		{"testdata/input.txt", 1796},
-	}
-
-	for _, tc := range cases {
-		f := readForest(t, tc.name)
-
-		got, err := Part1(f)
-		if err != nil {
-			t.Errorf("%q: %v", tc.name, err)
-		}
-		if got != tc.want {
-			t.Errorf("%q: got %d, want %d", tc.name, got, tc.want)
-		}
-	}
+	aoc22.CheckAnswers(t, 8, 1)
 }
 
 func TestForest_ScenicScore(t *testing.T) {
@@ -117,18 +107,35 @@ func TestForest_ScenicScore(t *testing.T) {
 }
 
 func TestPart2(t *testing.T) {
-	cases := []struct {
-		name string
-		want int
-	}{
-		{"testdata/small.txt", 8},
-		{"testdata/input.txt", 288120},
+	aoc22.CheckAnswers(t, 8, 2)
+}
+
+func BenchmarkPart1(b *testing.B) {
+	data := aoc22.ReadTestFile(b, "testdata/input.txt")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f, err := NewForest(bytes.NewReader(data))
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := Part1(context.Background(), f); err != nil {
+			b.Fatal(err)
+		}
 	}
+}
 
-	for _, tc := range cases {
-		f := readForest(t, tc.name)
-		if got := Part2(f); got != tc.want {
-			t.Errorf("%q: got %d, want %d", tc.name, got, tc.want)
+func BenchmarkPart2(b *testing.B) {
+	data := aoc22.ReadTestFile(b, "testdata/input.txt")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f, err := NewForest(bytes.NewReader(data))
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := Part2(context.Background(), f); err != nil {
+			b.Fatal(err)
 		}
 	}
 }
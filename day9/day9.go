@@ -2,36 +2,22 @@ package day9
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"io"
-	"math"
 	"strconv"
 	"strings"
-)
-
-type Vec2 struct {
-	X, Y int
-}
-
-func (v Vec2) Add(w Vec2) Vec2 {
-	return Vec2{v.X + w.X, v.Y + w.Y}
-}
-
-func (v Vec2) Sub(w Vec2) Vec2 {
-	return Vec2{v.X - w.X, v.Y - w.Y}
-}
-
-func (v Vec2) Dist(w Vec2) float64 {
-	return math.Sqrt(float64((v.X-w.X)*(v.X-w.X) + (v.Y-w.Y)*(v.Y-w.Y)))
-}
 
-func (v Vec2) Mag() float64 {
-	return math.Sqrt(float64(v.X*v.X + v.Y*v.Y))
-}
+	"github.com/clfs/aoc22"
+	"github.com/clfs/aoc22/anim"
+	"github.com/clfs/aoc22/geom"
+)
 
 type Rope struct {
-	knots []Vec2
-	seen  map[Vec2]bool
+	knots []geom.Point
+	seen  map[geom.Point]bool
+
+	Anim *anim.Recorder // If set, gets a frame for every step of the head.
 }
 
 func NewRope(n int) (*Rope, error) {
@@ -39,64 +25,81 @@ func NewRope(n int) (*Rope, error) {
 		return nil, fmt.Errorf("invalid rope length: %d", n)
 	}
 	return &Rope{
-		knots: make([]Vec2, n),
-		seen:  make(map[Vec2]bool),
+		knots: make([]geom.Point, n),
+		seen:  make(map[geom.Point]bool),
 	}, nil
 }
 
-func (r *Rope) Tail() Vec2 {
+func (r *Rope) Tail() geom.Point {
 	return r.knots[len(r.knots)-1]
 }
 
-func (r *Rope) TailsSeen() []Vec2 {
-	seen := make([]Vec2, 0, len(r.seen))
+func (r *Rope) TailsSeen() []geom.Point {
+	seen := make([]geom.Point, 0, len(r.seen))
 	for v := range r.seen {
 		seen = append(seen, v)
 	}
 	return seen
 }
 
-func (r *Rope) update(dir Vec2) {
+func (r *Rope) update(dir geom.Point) {
+	r.erase()
+
 	r.knots[0] = r.knots[0].Add(dir)
 
 	for i := 1; i < len(r.knots); i++ {
-		gap := r.knots[i-1].Sub(r.knots[i])
-
-		// easy case, we're close enough to the next knot
-		if gap.Mag() < 2 {
+		// Knots that are still touching don't move.
+		if r.knots[i-1].Chebyshev(r.knots[i]) < 2 {
 			continue
 		}
-
-		// otherwise, we need to move the knot
-		switch gap {
-		case Vec2{2, 0}:
-			dir = Vec2{1, 0}
-		case Vec2{-2, 0}:
-			dir = Vec2{-1, 0}
-		case Vec2{0, 2}:
-			dir = Vec2{0, 1}
-		case Vec2{0, -2}:
-			dir = Vec2{0, -1}
-		case Vec2{2, 1}, Vec2{1, 2}, Vec2{2, 2}:
-			dir = Vec2{1, 1}
-		case Vec2{2, -1}, Vec2{1, -2}, Vec2{2, -2}:
-			dir = Vec2{1, -1}
-		case Vec2{-2, 1}, Vec2{-1, 2}, Vec2{-2, 2}:
-			dir = Vec2{-1, 1}
-		case Vec2{-2, -1}, Vec2{-1, -2}, Vec2{-2, -2}:
-			dir = Vec2{-1, -1}
-		default:
-			panic(fmt.Sprintf("%v: bad gap %v", r.knots, gap))
-		}
-
-		r.knots[i] = r.knots[i].Add(dir)
+		r.knots[i] = r.knots[i].StepToward(r.knots[i-1])
 	}
 
 	r.seen[r.Tail()] = true
 
+	r.draw()
+
 	// log.Printf("%v\n", r.Debug(0, 0, 5, 5))
 }
 
+// erase undraws the knots from r.Anim, leaving the cells the tail has visited.
+func (r *Rope) erase() {
+	if r.Anim == nil {
+		return
+	}
+	for _, k := range r.knots {
+		c := anim.Background
+		if r.seen[k] {
+			c = anim.Blue
+		}
+		r.Anim.Set(animPoint(k), c)
+	}
+}
+
+// draw draws the knots on r.Anim and ends the frame. The head is drawn last,
+// so it's on top.
+func (r *Rope) draw() {
+	if r.Anim == nil {
+		return
+	}
+	for i := len(r.knots) - 1; i >= 0; i-- {
+		c := anim.White
+		switch i {
+		case 0:
+			c = anim.Red
+		case len(r.knots) - 1:
+			c = anim.Orange
+		}
+		r.Anim.Set(animPoint(r.knots[i]), c)
+	}
+	r.Anim.Frame()
+}
+
+// animPoint flips p vertically, since images grow downwards.
+func animPoint(p geom.Point) geom.Point {
+	return geom.Pt(p.X, -p.Y)
+}
+
 func (r *Rope) Debug(x0, y0, x1, y1 int) string {
 	// make an (x1-x0+1) x (y1-y0+1) grid
 	grid := make([][]rune, x1-x0+1)
@@ -108,7 +111,9 @@ func (r *Rope) Debug(x0, y0, x1, y1 int) string {
 	}
 
 	// mark the starting point
-	grid[0][0] = 's'
+	if x0 <= 0 && 0 <= x1 && y0 <= 0 && 0 <= y1 {
+		grid[-y0][-x0] = 's'
+	}
 
 	// mark the knots with their index, iterating backwards
 	for i := len(r.knots) - 1; i >= 0; i-- {
@@ -157,16 +162,17 @@ func (i Instruction) String() string {
 }
 
 func (r *Rope) Follow(ins Instruction) error {
-	var v Vec2
+	// Unlike in geom, Y grows upwards here, to match Debug.
+	var v geom.Point
 	switch ins.Direction {
 	case "U":
-		v = Vec2{Y: 1}
+		v = geom.Pt(0, 1)
 	case "L":
-		v = Vec2{X: -1}
+		v = geom.Pt(-1, 0)
 	case "D":
-		v = Vec2{Y: -1}
+		v = geom.Pt(0, -1)
 	case "R":
-		v = Vec2{X: 1}
+		v = geom.Pt(1, 0)
 	default:
 		return fmt.Errorf("bad direction: %s", ins.Direction)
 	}
@@ -181,31 +187,111 @@ func (r *Rope) Follow(ins Instruction) error {
 	return nil
 }
 
-func Part1(r io.Reader) (int, error) {
-	return solve(r, 2)
+func Part1(ctx context.Context, r io.Reader) (aoc22.Answer, error) {
+	return solve(ctx, r, 2)
 }
 
-func Part2(r io.Reader) (int, error) {
-	return solve(r, 10)
+func Part2(ctx context.Context, r io.Reader) (aoc22.Answer, error) {
+	return solve(ctx, r, 10)
 }
 
-func solve(r io.Reader, n int) (int, error) {
+func solve(ctx context.Context, r io.Reader, n int) (aoc22.Answer, error) {
 	rope, err := NewRope(n)
 	if err != nil {
-		return 0, err
+		return aoc22.Answer{}, err
 	}
+	rope.Anim = anim.RecorderFrom(ctx)
 
 	var ins Instruction
 
 	s := bufio.NewScanner(r)
 	for s.Scan() {
 		if err := ins.UnmarshalText(s.Bytes()); err != nil {
-			return 0, err
+			return aoc22.Answer{}, err
 		}
 		if err := rope.Follow(ins); err != nil {
-			return 0, err
+			return aoc22.Answer{}, err
+		}
+	}
+
+	return aoc22.Int(len(rope.TailsSeen())), s.Err()
+}
+
+// Simulate starts a simulation of the rope, one step of the head at a time.
+// Part 1 has 2 knots, and part 2 has 10.
+func Simulate(part int, r io.Reader) (aoc22.Simulation, error) {
+	n := 2
+	if part == 2 {
+		n = 10
+	}
+	rope, err := NewRope(n)
+	if err != nil {
+		return nil, err
+	}
+
+	var moves []Instruction
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		var ins Instruction
+		if err := ins.UnmarshalText(s.Bytes()); err != nil {
+			return nil, err
 		}
+		moves = append(moves, ins)
 	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	return &simulation{rope: rope, moves: moves}, nil
+}
+
+// The distance from the head to the edge of the rendered window.
+const simulationRadius = 12
+
+type simulation struct {
+	rope  *Rope
+	moves []Instruction
+	done  int // The steps done of moves[0].
+}
+
+func (s *simulation) Step() bool {
+	for len(s.moves) > 0 && s.done == s.moves[0].Count {
+		s.moves, s.done = s.moves[1:], 0
+	}
+	if len(s.moves) == 0 {
+		return false
+	}
+
+	step := s.moves[0]
+	step.Count = 1
+	if err := s.rope.Follow(step); err != nil {
+		return false
+	}
+	s.done++
+	return true
+}
+
+func (s *simulation) Render() string {
+	head := s.rope.knots[0]
+	window := s.rope.Debug(
+		head.X-simulationRadius, head.Y-simulationRadius,
+		head.X+simulationRadius, head.Y+simulationRadius,
+	)
+
+	next := "done"
+	if len(s.moves) > 0 {
+		next = fmt.Sprintf("%v (%d/%d)", s.moves[0], s.done, s.moves[0].Count)
+	}
+	return fmt.Sprintf("move %s, tail visited %d\n\n%s", next, len(s.rope.seen), window)
+}
 
-	return len(rope.TailsSeen()), s.Err()
+func init() {
+	aoc22.Register(aoc22.Puzzle{
+		Day:   9,
+		Part1: func(ctx context.Context, r io.Reader, _ aoc22.Params) (aoc22.Answer, error) { return Part1(ctx, r) },
+		Part2: func(ctx context.Context, r io.Reader, _ aoc22.Params) (aoc22.Answer, error) { return Part2(ctx, r) },
+		Simulate: func(part int, r io.Reader, _ aoc22.Params) (aoc22.Simulation, error) {
+			return Simulate(part, r)
+		},
+	})
 }
@@ -1,63 +1,104 @@
 package day9
 
 import (
-	"os"
+	"bytes"
+	"context"
+	"math/rand"
+	"reflect"
 	"testing"
+	"testing/quick"
+
+	"github.com/clfs/aoc22"
+	"github.com/clfs/aoc22/anim"
+	"github.com/clfs/aoc22/geom"
 )
 
 func TestPart1(t *testing.T) {
-	cases := []struct {
-		name string
-		want int
-	}{
-		{"testdata/small.txt", 13},
-		{"testdata/input.txt", 6090},
-	}
-
-	for _, tc := range cases {
-		t.Run(tc.name, func(t *testing.T) {
-			f, err := os.Open(tc.name)
-			if err != nil {
-				t.Fatal(err)
-			}
-			defer f.Close()
+	aoc22.CheckAnswers(t, 9, 1)
+}
 
-			got, err := Part1(f)
-			if err != nil {
-				t.Errorf("error: %v", err)
-			}
-			if got != tc.want {
-				t.Errorf("got %d, want %d", got, tc.want)
-			}
+func TestPart1_Anim(t *testing.T) {
+	var r anim.Recorder
+	ctx := anim.WithRecorder(context.Background(), &r)
+	if _, err := Part1(ctx, bytes.NewReader(aoc22.ReadTestFile(t, "testdata/small.txt"))); err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Len() != 24 {
+		t.Errorf("got %d frames, want one for each of the 24 steps", r.Len())
+	}
+	// The head ends up at (2, 2), and images grow downwards.
+	if c := r.At(geom.Pt(2, -2)); c != anim.Red {
+		t.Errorf("head cell is color %d, want red", c)
+	}
+}
+
+// randMotions is a random rope of 2 to 10 knots and the motions of its head,
+// for property tests.
+type randMotions struct {
+	knots   int
+	motions []Instruction
+}
+
+func (randMotions) Generate(rng *rand.Rand, size int) reflect.Value {
+	m := randMotions{knots: 2 + rng.Intn(9)}
+	for i := rng.Intn(30); i > 0; i-- {
+		m.motions = append(m.motions, Instruction{
+			Direction: string("UDLR"[rng.Intn(4)]),
+			Count:     1 + rng.Intn(8),
 		})
 	}
+	return reflect.ValueOf(m)
 }
 
-func TestPart2(t *testing.T) {
-	cases := []struct {
-		name string
-		want int
-	}{
-		{"testdata/small.txt", 1},
-		{"testdata/large.txt", 36},
-		{"testdata/input.txt", 2566},
-	}
-
-	for _, tc := range cases {
-		t.Run(tc.name, func(t *testing.T) {
-			f, err := os.Open(tc.name)
-			if err != nil {
-				t.Fatal(err)
+func TestRope_NoGaps(t *testing.T) {
+	f := func(m randMotions) bool {
+		r, err := NewRope(m.knots)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, ins := range m.motions {
+			// One step at a time, to check the rope after every step.
+			for i := 0; i < ins.Count; i++ {
+				if err := r.Follow(Instruction{ins.Direction, 1}); err != nil {
+					t.Fatal(err)
+				}
+				for k := 1; k < len(r.knots); k++ {
+					if r.knots[k-1].Chebyshev(r.knots[k]) >= 2 {
+						return false
+					}
+				}
 			}
-			defer f.Close()
+		}
+		return true
+	}
+	if err := quick.Check(f, &quick.Config{MaxCount: 1000}); err != nil {
+		t.Error(err)
+	}
+}
 
-			got, err := Part2(f)
-			if err != nil {
-				t.Errorf("error: %v", err)
-			}
-			if got != tc.want {
-				t.Errorf("got %d, want %d", got, tc.want)
-			}
-		})
+func TestPart2(t *testing.T) {
+	aoc22.CheckAnswers(t, 9, 2)
+}
+
+func BenchmarkPart1(b *testing.B) {
+	data := aoc22.ReadTestFile(b, "testdata/input.txt")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Part1(context.Background(), bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPart2(b *testing.B) {
+	data := aoc22.ReadTestFile(b, "testdata/input.txt")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Part2(context.Background(), bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
 	}
 }
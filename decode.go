@@ -0,0 +1,189 @@
+package aoc22
+
+import (
+	"bufio"
+	"encoding"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Decode matches line against re and stores its named capture groups in the
+// struct that v points to.
+//
+// A field receives the group named by its "aoc" tag. Fields without one are
+// left alone. Groups that don't take part in the match leave their field
+// unchanged. A field may be:
+//
+//   - any integer type, parsed in base 10
+//   - a string
+//   - a slice of integers or strings, split on the field's "sep" tag, or on
+//     whitespace if there is none
+//   - any type whose pointer implements encoding.TextUnmarshaler
+//
+// For example:
+//
+//	var valveRegexp = regexp.MustCompile(`^(?P<name>\w+) -> (?P<to>.*)$`)
+//
+//	type Valve struct {
+//		Name string   `aoc:"name"`
+//		To   []string `aoc:"to" sep:", "`
+//	}
+//
+//	var v Valve
+//	err := aoc22.Decode(valveRegexp, "AA -> BB, CC", &v)
+//
+// If line doesn't match, or a group can't be stored in its field, Decode
+// returns a *ParseError. The error's Column points at the offending group, if
+// there is one. Other problems, like a tag naming a group that re doesn't have,
+// are the caller's bug and return ordinary errors.
+func Decode(re *regexp.Regexp, line string, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("aoc22: Decode of %T, want a pointer to a struct", v)
+	}
+	rv = rv.Elem()
+
+	loc := re.FindStringSubmatchIndex(line)
+	if loc == nil {
+		return &ParseError{Err: fmt.Errorf("doesn't match %s", re)}
+	}
+
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		name, ok := field.Tag.Lookup("aoc")
+		if !ok {
+			continue
+		}
+
+		if !field.IsExported() {
+			return fmt.Errorf("aoc22: field %s.%s is tagged but unexported", rt, field.Name)
+		}
+		g := re.SubexpIndex(name)
+		if g < 0 {
+			return fmt.Errorf("aoc22: field %s.%s wants group %q, which %s doesn't have", rt, field.Name, name, re)
+		}
+		lo, hi := loc[2*g], loc[2*g+1]
+		if lo < 0 {
+			continue
+		}
+
+		if err := decodeField(rv.Field(i), line[lo:hi], field.Tag.Get("sep")); err != nil {
+			if de, ok := err.(*decodeError); ok {
+				lo += de.offset
+				err = de.err
+			}
+			return &ParseError{Column: lo + 1, Err: fmt.Errorf("%s: %w", name, err)}
+		}
+	}
+	return nil
+}
+
+// A decodeError is an error converting one element of a slice, at the given
+// offset into the group.
+type decodeError struct {
+	offset int
+	err    error
+}
+
+func (e *decodeError) Error() string {
+	return e.err.Error()
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// decodeField stores s in the field f.
+func decodeField(f reflect.Value, s, sep string) error {
+	if f.CanAddr() && f.Addr().Type().Implements(textUnmarshalerType) {
+		return f.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(s)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetInt(n)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetUint(n)
+
+	case reflect.Slice:
+		elems, offsets := split(s, sep)
+		slice := reflect.MakeSlice(f.Type(), len(elems), len(elems))
+		for i, elem := range elems {
+			if err := decodeField(slice.Index(i), elem, ""); err != nil {
+				return &decodeError{offset: offsets[i], err: err}
+			}
+		}
+		f.Set(slice)
+
+	default:
+		return fmt.Errorf("can't decode into %s", f.Type())
+	}
+	return nil
+}
+
+// split splits s on sep, or on runs of whitespace if sep is empty. It also
+// returns the offset of each element in s.
+func split(s, sep string) (elems []string, offsets []int) {
+	if sep == "" {
+		start := -1
+		for i, r := range s + " " {
+			switch space := unicode.IsSpace(r); {
+			case space && start >= 0:
+				elems = append(elems, s[start:i])
+				offsets = append(offsets, start)
+				start = -1
+			case !space && start < 0:
+				start = i
+			}
+		}
+		return elems, offsets
+	}
+
+	var offset int
+	for {
+		i := strings.Index(s[offset:], sep)
+		if i < 0 {
+			elems = append(elems, s[offset:])
+			offsets = append(offsets, offset)
+			return elems, offsets
+		}
+		elems = append(elems, s[offset:offset+i])
+		offsets = append(offsets, offset)
+		offset += i + len(sep)
+	}
+}
+
+// DecodeLines reads r line by line, decoding each line into a T with Decode.
+//
+// Errors are returned as *ParseError with the line number filled in. The
+// caller knows the day and can set it.
+func DecodeLines[T any](r io.Reader, re *regexp.Regexp) ([]T, error) {
+	var result []T
+
+	s := bufio.NewScanner(r)
+	for line := 1; s.Scan(); line++ {
+		var v T
+		if err := Decode(re, s.Text(), &v); err != nil {
+			return nil, NewParseError(0, line, s.Text(), err)
+		}
+		result = append(result, v)
+	}
+
+	return result, s.Err()
+}
@@ -0,0 +1,96 @@
+package aoc22
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var testRegexp = regexp.MustCompile(`^(?P<name>[A-Z]+) (?P<n>-?\d+)(?: \[(?P<tags>.*)\])?(?: (?P<nums>[\d ]+))?$`)
+
+type testRecord struct {
+	Name string   `aoc:"name"`
+	N    int8     `aoc:"n"`
+	Tags []string `aoc:"tags" sep:", "`
+	Nums []int    `aoc:"nums"`
+	Skip int
+}
+
+func TestDecode(t *testing.T) {
+	cases := []struct {
+		in   string
+		want testRecord
+	}{
+		{"AB 12", testRecord{Name: "AB", N: 12}},
+		{"AB -3 [x, y]", testRecord{Name: "AB", N: -3, Tags: []string{"x", "y"}}},
+		{"C 0 [z] 1  22 3", testRecord{Name: "C", Tags: []string{"z"}, Nums: []int{1, 22, 3}}},
+	}
+
+	for _, tc := range cases {
+		var got testRecord
+		if err := Decode(testRegexp, tc.in, &got); err != nil {
+			t.Errorf("Decode(%q) error: %v", tc.in, err)
+			continue
+		}
+		if diff := cmp.Diff(tc.want, got); diff != "" {
+			t.Errorf("Decode(%q) mismatch (-want +got):\n%s", tc.in, diff)
+		}
+	}
+}
+
+func TestDecode_Error(t *testing.T) {
+	cases := []struct {
+		in  string
+		col int
+	}{
+		{"ab 12", 0},
+		{"AB 300", 4},
+		{"AB 1 [x] 1 99999999999999999999", 12},
+	}
+
+	for _, tc := range cases {
+		var v testRecord
+		err := Decode(testRegexp, tc.in, &v)
+
+		var pe *ParseError
+		if !errors.As(err, &pe) {
+			t.Errorf("Decode(%q) error = %v, want a ParseError", tc.in, err)
+			continue
+		}
+		if pe.Column != tc.col {
+			t.Errorf("Decode(%q) error = %v, want column %d", tc.in, err, tc.col)
+		}
+	}
+}
+
+func TestDecode_BadTarget(t *testing.T) {
+	var missing struct {
+		X int `aoc:"x"`
+	}
+	if err := Decode(testRegexp, "AB 12", &missing); err == nil {
+		t.Error("Decode with a missing group succeeded")
+	}
+	if err := Decode(testRegexp, "AB 12", testRecord{}); err == nil {
+		t.Error("Decode into a non-pointer succeeded")
+	}
+}
+
+func TestDecodeLines(t *testing.T) {
+	got, err := DecodeLines[testRecord](strings.NewReader("A 1\nB 2\n"), testRegexp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []testRecord{{Name: "A", N: 1}, {Name: "B", N: 2}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("DecodeLines mismatch (-want +got):\n%s", diff)
+	}
+
+	_, err = DecodeLines[testRecord](strings.NewReader("A 1\nB x\n"), testRegexp)
+	var pe *ParseError
+	if !errors.As(err, &pe) || pe.Line != 2 {
+		t.Errorf("DecodeLines error = %v, want a ParseError on line 2", err)
+	}
+}
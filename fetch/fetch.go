@@ -0,0 +1,236 @@
+// Package fetch downloads puzzle inputs from Advent of Code and caches them on
+// disk, so each input is only ever downloaded once.
+//
+// Downloading an input needs the session token from a logged-in browser's
+// "session" cookie. See Session for where it's looked up.
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultBaseURL is the Advent of Code website.
+	DefaultBaseURL = "https://adventofcode.com"
+
+	// DefaultUserAgent identifies this tool to the website, as its maintainer
+	// asks automated tools to do.
+	DefaultUserAgent = "github.com/clfs/aoc22/fetch"
+
+	// DefaultInterval is the minimum time between two requests by a Client.
+	DefaultInterval = 3 * time.Second
+
+	// SessionEnv is the environment variable holding the session token.
+	SessionEnv = "AOC_SESSION"
+)
+
+// ErrNoSession is returned when an input isn't cached and there's no session
+// token to download it with.
+var ErrNoSession = errors.New("fetch: no session token; set " + SessionEnv + " or write it to the session file")
+
+// A StatusError is returned when the website answers with an unexpected HTTP
+// status, such as 400 for an expired session token or 404 for a puzzle that
+// isn't out yet.
+type StatusError struct {
+	StatusCode int
+	Body       string // The start of the response body.
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("fetch: unexpected status %d %s: %q",
+		e.StatusCode, http.StatusText(e.StatusCode), e.Body)
+}
+
+// A Client downloads and caches puzzle inputs. Its zero value is not usable;
+// at least CacheDir must be set. A Client is safe for concurrent use.
+type Client struct {
+	BaseURL    string        // Defaults to DefaultBaseURL.
+	Session    string        // The session token; needed to download inputs.
+	CacheDir   string        // Where inputs are cached. Required.
+	UserAgent  string        // Defaults to DefaultUserAgent.
+	Interval   time.Duration // The minimum time between requests; defaults to DefaultInterval.
+	HTTPClient *http.Client  // Defaults to http.DefaultClient.
+
+	mu   sync.Mutex
+	last time.Time // When the last request was sent.
+}
+
+// CachePath returns where the input for a year and day is cached.
+func (c *Client) CachePath(year, day int) string {
+	return filepath.Join(c.CacheDir, strconv.Itoa(year), fmt.Sprintf("day%d.txt", day))
+}
+
+// Input returns the puzzle input for a year and day. It reads the input from
+// the cache if it's there, and otherwise downloads and caches it.
+func (c *Client) Input(ctx context.Context, year, day int) ([]byte, error) {
+	if day < 1 || day > 25 {
+		return nil, fmt.Errorf("fetch: invalid day %d", day)
+	}
+	if c.CacheDir == "" {
+		return nil, errors.New("fetch: no cache directory")
+	}
+
+	path := c.CachePath(year, day)
+	data, err := os.ReadFile(path)
+	if err == nil {
+		return data, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	if c.Session == "" {
+		return nil, ErrNoSession
+	}
+
+	data, err = c.download(ctx, year, day)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeFile(path, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (c *Client) download(ctx context.Context, year, day int) ([]byte, error) {
+	base := c.BaseURL
+	if base == "" {
+		base = DefaultBaseURL
+	}
+	url := fmt.Sprintf("%s/%d/day/%d/input", strings.TrimSuffix(base, "/"), year, day)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.AddCookie(&http.Cookie{Name: "session", Value: c.Session})
+	ua := c.UserAgent
+	if ua == "" {
+		ua = DefaultUserAgent
+	}
+	req.Header.Set("User-Agent", ua)
+
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, &StatusError{StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(body))}
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// wait blocks until the Client may send another request, then records that
+// it's sending one.
+func (c *Client) wait(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	interval := c.Interval
+	if interval == 0 {
+		interval = DefaultInterval
+	}
+
+	if d := time.Until(c.last.Add(interval)); d > 0 {
+		t := time.NewTimer(d)
+		defer t.Stop()
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	c.last = time.Now()
+	return nil
+}
+
+// writeFile writes data to path, creating its directory if needed. The file
+// appears all at once, so an interrupted download never leaves a partial
+// input in the cache.
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), ".download-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// Session returns the session token. It's taken from the environment variable
+// named by SessionEnv if that's set, and otherwise from the file returned by
+// SessionFile. If neither has a token, Session returns "" and no error.
+func Session() (string, error) {
+	if s := strings.TrimSpace(os.Getenv(SessionEnv)); s != "" {
+		return s, nil
+	}
+
+	path, err := SessionFile()
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// SessionFile returns the path of the file holding the session token, under
+// the user's configuration directory.
+func SessionFile() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "aoc22", "session"), nil
+}
+
+// DefaultCacheDir returns the default cache directory, under the user's cache
+// directory.
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "aoc22"), nil
+}
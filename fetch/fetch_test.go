@@ -0,0 +1,162 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newServer returns a stand-in for the website that serves an input for any
+// day to the session "good", and counts the requests it gets.
+func newServer(t *testing.T) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var n int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&n, 1)
+
+		if ua := r.Header.Get("User-Agent"); ua != DefaultUserAgent {
+			t.Errorf("User-Agent = %q, want %q", ua, DefaultUserAgent)
+		}
+
+		c, err := r.Cookie("session")
+		if err != nil || c.Value != "good" {
+			http.Error(w, "Puzzle inputs differ by user.  Please log in to get your puzzle input.", http.StatusBadRequest)
+			return
+		}
+
+		var year, day int
+		if _, err := fmt.Sscanf(r.URL.Path, "/%d/day/%d/input", &year, &day); err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, "input for %d day %d\n", year, day)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, &n
+}
+
+func TestClient_Input(t *testing.T) {
+	srv, n := newServer(t)
+	c := &Client{
+		BaseURL:  srv.URL,
+		Session:  "good",
+		CacheDir: t.TempDir(),
+		Interval: time.Millisecond,
+	}
+
+	for i := 0; i < 3; i++ {
+		got, err := c.Input(context.Background(), 2022, 7)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "input for 2022 day 7\n"; string(got) != want {
+			t.Errorf("Input() = %q, want %q", got, want)
+		}
+	}
+
+	if got := atomic.LoadInt32(n); got != 1 {
+		t.Errorf("server got %d requests, want 1", got)
+	}
+
+	cached, err := os.ReadFile(filepath.Join(c.CacheDir, "2022", "day7.txt"))
+	if err != nil || string(cached) != "input for 2022 day 7\n" {
+		t.Errorf("cached input = %q, %v", cached, err)
+	}
+}
+
+func TestClient_Input_CacheWithoutSession(t *testing.T) {
+	srv, n := newServer(t)
+	c := &Client{BaseURL: srv.URL, CacheDir: t.TempDir()}
+
+	if _, err := c.Input(context.Background(), 2022, 1); !errors.Is(err, ErrNoSession) {
+		t.Errorf("Input() error = %v, want ErrNoSession", err)
+	}
+
+	if err := writeFile(c.CachePath(2022, 1), []byte("cached\n")); err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.Input(context.Background(), 2022, 1)
+	if err != nil || string(got) != "cached\n" {
+		t.Errorf("Input() = %q, %v, want the cached input", got, err)
+	}
+
+	if got := atomic.LoadInt32(n); got != 0 {
+		t.Errorf("server got %d requests, want 0", got)
+	}
+}
+
+func TestClient_Input_BadSession(t *testing.T) {
+	srv, _ := newServer(t)
+	c := &Client{BaseURL: srv.URL, Session: "expired", CacheDir: t.TempDir()}
+
+	_, err := c.Input(context.Background(), 2022, 3)
+
+	var se *StatusError
+	if !errors.As(err, &se) || se.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Input() error = %v, want a 400 StatusError", err)
+	}
+	if _, err := os.Stat(c.CachePath(2022, 3)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("failed download was cached: %v", err)
+	}
+}
+
+func TestClient_Throttle(t *testing.T) {
+	srv, _ := newServer(t)
+	c := &Client{
+		BaseURL:  srv.URL,
+		Session:  "good",
+		CacheDir: t.TempDir(),
+		Interval: 50 * time.Millisecond,
+	}
+
+	start := time.Now()
+	for day := 1; day <= 3; day++ {
+		if _, err := c.Input(context.Background(), 2022, day); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("3 downloads took %v, want at least 100ms", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.Input(ctx, 2022, 4); !errors.Is(err, context.Canceled) {
+		t.Errorf("Input() with a canceled context error = %v, want context.Canceled", err)
+	}
+}
+
+func TestSession(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir()) // For macOS.
+	t.Setenv(SessionEnv, "")
+
+	if s, err := Session(); s != "" || err != nil {
+		t.Errorf("Session() = %q, %v, want no session", s, err)
+	}
+
+	path, err := SessionFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFile(path, []byte("from-file\n")); err != nil {
+		t.Fatal(err)
+	}
+	if s, err := Session(); s != "from-file" || err != nil {
+		t.Errorf("Session() = %q, %v, want the file's session", s, err)
+	}
+
+	t.Setenv(SessionEnv, "from-env")
+	if s, err := Session(); s != "from-env" || err != nil {
+		t.Errorf("Session() = %q, %v, want the environment's session", s, err)
+	}
+}
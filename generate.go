@@ -0,0 +1,29 @@
+package aoc22
+
+import (
+	"io"
+	"math/rand"
+)
+
+// A Generator writes a random puzzle input to w, drawing from rng so that the
+// same seed always gives the same input. The size scales the input, in a way
+// that depends on the day, like the number of valves in day 16; if it's 0, the
+// input is about as large as a real one.
+//
+// Where the construction of the input fixes an answer, like the one gap left
+// between the sensors of day 15, the Generator returns it.
+type Generator func(w io.Writer, rng *rand.Rand, size int) (Generated, error)
+
+// Generated describes a generated input.
+type Generated struct {
+	Params  Params    // Parameters to solve the input with. Missing ones take their defaults.
+	Answers [2]Answer // The answers to parts 1 and 2, or zero if they aren't known.
+}
+
+// Answer returns the known answer to part n, if there is one.
+func (g Generated) Answer(n int) (Answer, bool) {
+	if n < 1 || n > 2 || g.Answers[n-1].IsZero() {
+		return Answer{}, false
+	}
+	return g.Answers[n-1], true
+}
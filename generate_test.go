@@ -0,0 +1,17 @@
+package aoc22
+
+import "testing"
+
+func TestGenerated_Answer(t *testing.T) {
+	g := Generated{Answers: [2]Answer{1: Int(56000011)}}
+
+	if a, ok := g.Answer(1); ok {
+		t.Errorf("Answer(1) = %v, true; want no answer", a)
+	}
+	if a, ok := g.Answer(2); !ok || a != Int(56000011) {
+		t.Errorf("Answer(2) = %v, %t; want 56000011, true", a, ok)
+	}
+	if _, ok := g.Answer(3); ok {
+		t.Error("Answer(3) found an answer")
+	}
+}
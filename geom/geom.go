@@ -0,0 +1,187 @@
+// Package geom provides integer points in two and three dimensions, along with
+// the distances and bounding boxes that puzzles keep asking for.
+//
+// Points double as vectors: a step up is just the Point Up. In two dimensions,
+// Y grows downwards, like rows in a grid.
+package geom
+
+import "math"
+
+// A Point is a point or vector in two dimensions.
+type Point struct {
+	X, Y int
+}
+
+// Pt is shorthand for Point{x, y}.
+func Pt(x, y int) Point {
+	return Point{x, y}
+}
+
+// Directions, as unit steps. Treat them as constants.
+var (
+	Up        = Point{0, -1}
+	Down      = Point{0, 1}
+	Left      = Point{-1, 0}
+	Right     = Point{1, 0}
+	UpLeft    = Point{-1, -1}
+	UpRight   = Point{1, -1}
+	DownLeft  = Point{-1, 1}
+	DownRight = Point{1, 1}
+)
+
+// Add returns p+q.
+func (p Point) Add(q Point) Point {
+	return Point{p.X + q.X, p.Y + q.Y}
+}
+
+// Sub returns p-q.
+func (p Point) Sub(q Point) Point {
+	return Point{p.X - q.X, p.Y - q.Y}
+}
+
+// Mul returns p scaled by k.
+func (p Point) Mul(k int) Point {
+	return Point{p.X * k, p.Y * k}
+}
+
+// Sign returns p with each coordinate replaced by its sign: -1, 0, or 1.
+func (p Point) Sign() Point {
+	return Point{sign(p.X), sign(p.Y)}
+}
+
+// Clamp returns p with each coordinate limited to the range [lo, hi].
+func (p Point) Clamp(lo, hi int) Point {
+	return Point{clamp(p.X, lo, hi), clamp(p.Y, lo, hi)}
+}
+
+// StepToward returns p moved one step toward q, diagonally if needed. If p
+// equals q, it returns p.
+func (p Point) StepToward(q Point) Point {
+	return p.Add(q.Sub(p).Sign())
+}
+
+// Manhattan returns the taxicab distance between p and q.
+func (p Point) Manhattan(q Point) int {
+	return abs(p.X-q.X) + abs(p.Y-q.Y)
+}
+
+// Chebyshev returns the king's-move distance between p and q: the number of
+// steps between them when diagonal steps are allowed.
+func (p Point) Chebyshev(q Point) int {
+	return max(abs(p.X-q.X), abs(p.Y-q.Y))
+}
+
+// Euclidean returns the straight-line distance between p and q.
+func (p Point) Euclidean(q Point) float64 {
+	dx, dy := float64(p.X-q.X), float64(p.Y-q.Y)
+	return math.Sqrt(dx*dx + dy*dy)
+}
+
+// RotateCW returns p rotated a quarter turn clockwise around the origin, so
+// that Up becomes Right.
+func (p Point) RotateCW() Point {
+	return Point{-p.Y, p.X}
+}
+
+// RotateCCW returns p rotated a quarter turn counterclockwise around the
+// origin, so that Up becomes Left.
+func (p Point) RotateCCW() Point {
+	return Point{p.Y, -p.X}
+}
+
+// Neighbors4 returns the points above, right of, below, and left of p, in
+// that order.
+func (p Point) Neighbors4() []Point {
+	return []Point{p.Add(Up), p.Add(Right), p.Add(Down), p.Add(Left)}
+}
+
+// Neighbors8 returns the points around p, including diagonals, clockwise from
+// the point above.
+func (p Point) Neighbors8() []Point {
+	return []Point{
+		p.Add(Up), p.Add(UpRight), p.Add(Right), p.Add(DownRight),
+		p.Add(Down), p.Add(DownLeft), p.Add(Left), p.Add(UpLeft),
+	}
+}
+
+// A Rect is the set of points between Min and Max inclusive.
+type Rect struct {
+	Min, Max Point
+}
+
+// Bounds returns the smallest Rect containing every point. It panics if there
+// are no points.
+func Bounds(points []Point) Rect {
+	if len(points) == 0 {
+		panic("geom: bounds of no points")
+	}
+	r := Rect{points[0], points[0]}
+	for _, p := range points[1:] {
+		r = r.Include(p)
+	}
+	return r
+}
+
+// Include returns the smallest Rect containing both r and p.
+func (r Rect) Include(p Point) Rect {
+	return Rect{
+		Point{min(r.Min.X, p.X), min(r.Min.Y, p.Y)},
+		Point{max(r.Max.X, p.X), max(r.Max.Y, p.Y)},
+	}
+}
+
+// Expand returns r grown by n on every side. A negative n shrinks r.
+func (r Rect) Expand(n int) Rect {
+	return Rect{r.Min.Sub(Point{n, n}), r.Max.Add(Point{n, n})}
+}
+
+// Contains reports whether p is in r.
+func (r Rect) Contains(p Point) bool {
+	return r.Min.X <= p.X && p.X <= r.Max.X && r.Min.Y <= p.Y && p.Y <= r.Max.Y
+}
+
+// Width returns the number of columns in r.
+func (r Rect) Width() int {
+	return r.Max.X - r.Min.X + 1
+}
+
+// Height returns the number of rows in r.
+func (r Rect) Height() int {
+	return r.Max.Y - r.Min.Y + 1
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func sign(x int) int {
+	switch {
+	case x < 0:
+		return -1
+	case x > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func clamp(x, lo, hi int) int {
+	return max(lo, min(x, hi))
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,122 @@
+package geom
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPoint_Distances(t *testing.T) {
+	cases := []struct {
+		p, q      Point
+		manhattan int
+		chebyshev int
+		euclidean float64
+	}{
+		{Pt(0, 0), Pt(3, 4), 7, 4, 5},
+		{Pt(-2, 0), Pt(0, -1), 3, 2, math.Sqrt(5)},
+		{Pt(1, 1), Pt(1, 1), 0, 0, 0},
+	}
+
+	for _, tc := range cases {
+		if got := tc.p.Manhattan(tc.q); got != tc.manhattan {
+			t.Errorf("%v.Manhattan(%v) = %d, want %d", tc.p, tc.q, got, tc.manhattan)
+		}
+		if got := tc.p.Chebyshev(tc.q); got != tc.chebyshev {
+			t.Errorf("%v.Chebyshev(%v) = %d, want %d", tc.p, tc.q, got, tc.chebyshev)
+		}
+		if got := tc.p.Euclidean(tc.q); math.Abs(got-tc.euclidean) > 1e-9 {
+			t.Errorf("%v.Euclidean(%v) = %f, want %f", tc.p, tc.q, got, tc.euclidean)
+		}
+	}
+}
+
+func TestPoint_StepToward(t *testing.T) {
+	cases := []struct {
+		p, q, want Point
+	}{
+		{Pt(0, 0), Pt(2, 0), Pt(1, 0)},
+		{Pt(0, 0), Pt(2, 1), Pt(1, 1)},
+		{Pt(0, 0), Pt(-5, -9), Pt(-1, -1)},
+		{Pt(3, 3), Pt(3, 3), Pt(3, 3)},
+	}
+
+	for _, tc := range cases {
+		if got := tc.p.StepToward(tc.q); got != tc.want {
+			t.Errorf("%v.StepToward(%v) = %v, want %v", tc.p, tc.q, got, tc.want)
+		}
+	}
+}
+
+func TestPoint_Rotate(t *testing.T) {
+	dirs := []Point{Up, Right, Down, Left}
+	for i, d := range dirs {
+		next := dirs[(i+1)%len(dirs)]
+		if got := d.RotateCW(); got != next {
+			t.Errorf("%v.RotateCW() = %v, want %v", d, got, next)
+		}
+		if got := next.RotateCCW(); got != d {
+			t.Errorf("%v.RotateCCW() = %v, want %v", next, got, d)
+		}
+	}
+}
+
+func TestPoint_Clamp(t *testing.T) {
+	if got, want := Pt(5, -7).Clamp(-1, 1), Pt(1, -1); got != want {
+		t.Errorf("Clamp() = %v, want %v", got, want)
+	}
+}
+
+func TestBounds(t *testing.T) {
+	r := Bounds([]Point{Pt(3, -1), Pt(-2, 4), Pt(0, 0)})
+	if want := (Rect{Pt(-2, -1), Pt(3, 4)}); r != want {
+		t.Errorf("Bounds() = %v, want %v", r, want)
+	}
+	if r.Width() != 6 || r.Height() != 6 {
+		t.Errorf("Bounds() is %dx%d, want 6x6", r.Width(), r.Height())
+	}
+	if !r.Contains(Pt(3, 4)) || r.Contains(Pt(4, 4)) {
+		t.Errorf("%v.Contains() is wrong at the edges", r)
+	}
+	if got, want := r.Expand(1), (Rect{Pt(-3, -2), Pt(4, 5)}); got != want {
+		t.Errorf("Expand(1) = %v, want %v", got, want)
+	}
+}
+
+func TestPoint3(t *testing.T) {
+	p, q := Pt3(1, 2, 3), Pt3(4, 0, 3)
+
+	if got := p.Manhattan(q); got != 5 {
+		t.Errorf("Manhattan() = %d, want 5", got)
+	}
+	if got := p.Chebyshev(q); got != 3 {
+		t.Errorf("Chebyshev() = %d, want 3", got)
+	}
+	if got, want := p.StepToward(q), Pt3(2, 1, 3); got != want {
+		t.Errorf("StepToward() = %v, want %v", got, want)
+	}
+
+	for _, n := range p.Neighbors6() {
+		if p.Manhattan(n) != 1 {
+			t.Errorf("Neighbors6() includes %v, which isn't next to %v", n, p)
+		}
+	}
+
+	x, y, z := Pt3(1, 0, 0), Pt3(0, 1, 0), Pt3(0, 0, 1)
+	if got := y.RotateX(); got != z {
+		t.Errorf("RotateX(y) = %v, want %v", got, z)
+	}
+	if got := z.RotateY(); got != x {
+		t.Errorf("RotateY(z) = %v, want %v", got, x)
+	}
+	if got := x.RotateZ(); got != y {
+		t.Errorf("RotateZ(x) = %v, want %v", got, y)
+	}
+
+	b := Bounds3([]Point3{p, q}).Expand(1)
+	if want := (Box{Pt3(0, -1, 2), Pt3(5, 3, 4)}); b != want {
+		t.Errorf("Bounds3().Expand(1) = %v, want %v", b, want)
+	}
+	if !b.Contains(Pt3(5, 3, 4)) || b.Contains(Pt3(5, 3, 5)) {
+		t.Errorf("%v.Contains() is wrong at the edges", b)
+	}
+}
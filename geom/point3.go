@@ -0,0 +1,124 @@
+package geom
+
+import "math"
+
+// A Point3 is a point or vector in three dimensions.
+type Point3 struct {
+	X, Y, Z int
+}
+
+// Pt3 is shorthand for Point3{x, y, z}.
+func Pt3(x, y, z int) Point3 {
+	return Point3{x, y, z}
+}
+
+// Add returns p+q.
+func (p Point3) Add(q Point3) Point3 {
+	return Point3{p.X + q.X, p.Y + q.Y, p.Z + q.Z}
+}
+
+// Sub returns p-q.
+func (p Point3) Sub(q Point3) Point3 {
+	return Point3{p.X - q.X, p.Y - q.Y, p.Z - q.Z}
+}
+
+// Mul returns p scaled by k.
+func (p Point3) Mul(k int) Point3 {
+	return Point3{p.X * k, p.Y * k, p.Z * k}
+}
+
+// Sign returns p with each coordinate replaced by its sign: -1, 0, or 1.
+func (p Point3) Sign() Point3 {
+	return Point3{sign(p.X), sign(p.Y), sign(p.Z)}
+}
+
+// Clamp returns p with each coordinate limited to the range [lo, hi].
+func (p Point3) Clamp(lo, hi int) Point3 {
+	return Point3{clamp(p.X, lo, hi), clamp(p.Y, lo, hi), clamp(p.Z, lo, hi)}
+}
+
+// StepToward returns p moved one step toward q, diagonally if needed. If p
+// equals q, it returns p.
+func (p Point3) StepToward(q Point3) Point3 {
+	return p.Add(q.Sub(p).Sign())
+}
+
+// Manhattan returns the taxicab distance between p and q.
+func (p Point3) Manhattan(q Point3) int {
+	return abs(p.X-q.X) + abs(p.Y-q.Y) + abs(p.Z-q.Z)
+}
+
+// Chebyshev returns the number of steps between p and q when diagonal steps
+// are allowed.
+func (p Point3) Chebyshev(q Point3) int {
+	return max(abs(p.X-q.X), max(abs(p.Y-q.Y), abs(p.Z-q.Z)))
+}
+
+// Euclidean returns the straight-line distance between p and q.
+func (p Point3) Euclidean(q Point3) float64 {
+	dx, dy, dz := float64(p.X-q.X), float64(p.Y-q.Y), float64(p.Z-q.Z)
+	return math.Sqrt(dx*dx + dy*dy + dz*dz)
+}
+
+// Neighbors6 returns the points that share a face with p: one step either
+// way along X, then Y, then Z.
+func (p Point3) Neighbors6() []Point3 {
+	return []Point3{
+		{p.X - 1, p.Y, p.Z}, {p.X + 1, p.Y, p.Z},
+		{p.X, p.Y - 1, p.Z}, {p.X, p.Y + 1, p.Z},
+		{p.X, p.Y, p.Z - 1}, {p.X, p.Y, p.Z + 1},
+	}
+}
+
+// RotateX returns p rotated a quarter turn around the X axis, taking Y to Z.
+func (p Point3) RotateX() Point3 {
+	return Point3{p.X, -p.Z, p.Y}
+}
+
+// RotateY returns p rotated a quarter turn around the Y axis, taking Z to X.
+func (p Point3) RotateY() Point3 {
+	return Point3{p.Z, p.Y, -p.X}
+}
+
+// RotateZ returns p rotated a quarter turn around the Z axis, taking X to Y.
+func (p Point3) RotateZ() Point3 {
+	return Point3{-p.Y, p.X, p.Z}
+}
+
+// A Box is the set of points between Min and Max inclusive.
+type Box struct {
+	Min, Max Point3
+}
+
+// Bounds3 returns the smallest Box containing every point. It panics if there
+// are no points.
+func Bounds3(points []Point3) Box {
+	if len(points) == 0 {
+		panic("geom: bounds of no points")
+	}
+	b := Box{points[0], points[0]}
+	for _, p := range points[1:] {
+		b = b.Include(p)
+	}
+	return b
+}
+
+// Include returns the smallest Box containing both b and p.
+func (b Box) Include(p Point3) Box {
+	return Box{
+		Point3{min(b.Min.X, p.X), min(b.Min.Y, p.Y), min(b.Min.Z, p.Z)},
+		Point3{max(b.Max.X, p.X), max(b.Max.Y, p.Y), max(b.Max.Z, p.Z)},
+	}
+}
+
+// Expand returns b grown by n on every side. A negative n shrinks b.
+func (b Box) Expand(n int) Box {
+	return Box{b.Min.Sub(Point3{n, n, n}), b.Max.Add(Point3{n, n, n})}
+}
+
+// Contains reports whether p is in b.
+func (b Box) Contains(p Point3) bool {
+	return b.Min.X <= p.X && p.X <= b.Max.X &&
+		b.Min.Y <= p.Y && p.Y <= b.Max.Y &&
+		b.Min.Z <= p.Z && p.Z <= b.Max.Z
+}
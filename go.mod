@@ -1,8 +1,5 @@
 module github.com/clfs/aoc22
 
-go 1.19
+go 1.22
 
-require (
-	github.com/google/go-cmp v0.5.8
-	golang.org/x/exp v0.0.0-20221126150942-6ab00d035af9
-)
+require github.com/google/go-cmp v0.5.8
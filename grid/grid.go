@@ -0,0 +1,259 @@
+// Package grid implements a generic rectangular grid, like the maps and
+// screens in many puzzles. Cells are addressed by geom.Point, with the
+// top-left cell at (0, 0).
+package grid
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/clfs/aoc22"
+	"github.com/clfs/aoc22/geom"
+)
+
+// A Grid is a rectangular grid of cells.
+type Grid[T any] struct {
+	width, height int
+	cells         []T // row-major
+}
+
+// New returns a grid of the given size, with every cell set to the zero value.
+func New[T any](width, height int) *Grid[T] {
+	if width < 0 || height < 0 {
+		panic(fmt.Sprintf("grid: invalid size %dx%d", width, height))
+	}
+	return &Grid[T]{
+		width:  width,
+		height: height,
+		cells:  make([]T, width*height),
+	}
+}
+
+// FromRunes returns a grid built from rows of runes, using f to turn each rune
+// into a cell. All rows must have the same length.
+//
+// Errors are returned as *aoc22.ParseError, positioned at the offending row and
+// column.
+func FromRunes[T any](rows [][]rune, f func(p geom.Point, r rune) (T, error)) (*Grid[T], error) {
+	var width int
+	if len(rows) > 0 {
+		width = len(rows[0])
+	}
+
+	g := New[T](width, len(rows))
+	for y, row := range rows {
+		if len(row) != width {
+			return nil, &aoc22.ParseError{
+				Line: y + 1,
+				Text: string(row),
+				Err:  fmt.Errorf("row has %d cells, want %d", len(row), width),
+			}
+		}
+		for x, r := range row {
+			v, err := f(geom.Pt(x, y), r)
+			if err != nil {
+				return nil, &aoc22.ParseError{Line: y + 1, Column: x + 1, Text: string(row), Err: err}
+			}
+			g.cells[y*width+x] = v
+		}
+	}
+	return g, nil
+}
+
+// Parse reads a grid from r, one row per line. It's like FromRunes.
+func Parse[T any](r io.Reader, f func(p geom.Point, r rune) (T, error)) (*Grid[T], error) {
+	var rows [][]rune
+
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		rows = append(rows, []rune(s.Text()))
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	return FromRunes(rows, f)
+}
+
+// Width returns the number of columns.
+func (g *Grid[T]) Width() int {
+	return g.width
+}
+
+// Height returns the number of rows.
+func (g *Grid[T]) Height() int {
+	return g.height
+}
+
+// In reports whether p is inside the grid.
+func (g *Grid[T]) In(p geom.Point) bool {
+	return 0 <= p.X && p.X < g.width && 0 <= p.Y && p.Y < g.height
+}
+
+func (g *Grid[T]) index(p geom.Point) int {
+	if !g.In(p) {
+		panic(fmt.Sprintf("grid: %v out of bounds for %dx%d grid", p, g.width, g.height))
+	}
+	return p.Y*g.width + p.X
+}
+
+// At returns the cell at p. It panics if p is out of bounds.
+func (g *Grid[T]) At(p geom.Point) T {
+	return g.cells[g.index(p)]
+}
+
+// Set sets the cell at p. It panics if p is out of bounds.
+func (g *Grid[T]) Set(p geom.Point, v T) {
+	g.cells[g.index(p)] = v
+}
+
+// Fill sets every cell to v.
+func (g *Grid[T]) Fill(v T) {
+	for i := range g.cells {
+		g.cells[i] = v
+	}
+}
+
+// Count returns the number of cells for which f returns true.
+func (g *Grid[T]) Count(f func(T) bool) int {
+	var n int
+	for _, v := range g.cells {
+		if f(v) {
+			n++
+		}
+	}
+	return n
+}
+
+// Points returns the position of every cell, in row-major order.
+func (g *Grid[T]) Points() []geom.Point {
+	points := make([]geom.Point, 0, len(g.cells))
+	for y := 0; y < g.height; y++ {
+		for x := 0; x < g.width; x++ {
+			points = append(points, geom.Pt(x, y))
+		}
+	}
+	return points
+}
+
+func (g *Grid[T]) inBounds(points []geom.Point) []geom.Point {
+	result := points[:0]
+	for _, p := range points {
+		if g.In(p) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// Neighbors4 returns the in-bounds cells above, right of, below, and left of
+// p, in that order.
+func (g *Grid[T]) Neighbors4(p geom.Point) []geom.Point {
+	return g.inBounds(p.Neighbors4())
+}
+
+// Neighbors8 returns the in-bounds cells around p, including diagonals,
+// clockwise from the cell above.
+func (g *Grid[T]) Neighbors8(p geom.Point) []geom.Point {
+	return g.inBounds(p.Neighbors8())
+}
+
+// Row returns a copy of row y.
+func (g *Grid[T]) Row(y int) []T {
+	row := make([]T, g.width)
+	copy(row, g.cells[y*g.width:(y+1)*g.width])
+	return row
+}
+
+// Col returns a copy of column x.
+func (g *Grid[T]) Col(x int) []T {
+	col := make([]T, g.height)
+	for y := range col {
+		col[y] = g.At(geom.Pt(x, y))
+	}
+	return col
+}
+
+// Ray returns the cells seen when walking from p in steps of d until leaving
+// the grid. The cell at p itself isn't included.
+func (g *Grid[T]) Ray(p, d geom.Point) []T {
+	if d == (geom.Point{}) {
+		panic("grid: zero step")
+	}
+
+	var result []T
+	for q := p.Add(d); g.In(q); q = q.Add(d) {
+		result = append(result, g.At(q))
+	}
+	return result
+}
+
+// Clone returns a copy of g.
+func (g *Grid[T]) Clone() *Grid[T] {
+	c := New[T](g.width, g.height)
+	copy(c.cells, g.cells)
+	return c
+}
+
+// Window returns a copy of the cells between min and max inclusive. It panics
+// if either corner is out of bounds.
+func (g *Grid[T]) Window(min, max geom.Point) *Grid[T] {
+	g.index(min)
+	g.index(max)
+
+	w := New[T](max.X-min.X+1, max.Y-min.Y+1)
+	for y := 0; y < w.height; y++ {
+		copy(w.cells[y*w.width:(y+1)*w.width], g.cells[(min.Y+y)*g.width+min.X:])
+	}
+	return w
+}
+
+// Transpose returns a copy of g flipped over its main diagonal, so that rows
+// become columns.
+func (g *Grid[T]) Transpose() *Grid[T] {
+	t := New[T](g.height, g.width)
+	for y := 0; y < g.height; y++ {
+		for x := 0; x < g.width; x++ {
+			t.Set(geom.Pt(y, x), g.At(geom.Pt(x, y)))
+		}
+	}
+	return t
+}
+
+// RotateCW returns a copy of g rotated a quarter turn clockwise.
+func (g *Grid[T]) RotateCW() *Grid[T] {
+	r := New[T](g.height, g.width)
+	for y := 0; y < g.height; y++ {
+		for x := 0; x < g.width; x++ {
+			r.Set(geom.Pt(g.height-1-y, x), g.At(geom.Pt(x, y)))
+		}
+	}
+	return r
+}
+
+// RotateCCW returns a copy of g rotated a quarter turn counterclockwise.
+func (g *Grid[T]) RotateCCW() *Grid[T] {
+	r := New[T](g.height, g.width)
+	for y := 0; y < g.height; y++ {
+		for x := 0; x < g.width; x++ {
+			r.Set(geom.Pt(y, g.width-1-x), g.At(geom.Pt(x, y)))
+		}
+	}
+	return r
+}
+
+// Render draws the grid as text, using f to turn each cell into a rune. Every
+// row, including the last, ends in a newline.
+func (g *Grid[T]) Render(f func(T) rune) string {
+	var b strings.Builder
+	b.Grow((g.width + 1) * g.height)
+	for y := 0; y < g.height; y++ {
+		for _, v := range g.cells[y*g.width : (y+1)*g.width] {
+			b.WriteRune(f(v))
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
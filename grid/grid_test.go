@@ -0,0 +1,188 @@
+package grid
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/clfs/aoc22"
+	"github.com/clfs/aoc22/geom"
+	"github.com/google/go-cmp/cmp"
+)
+
+func identity(_ geom.Point, r rune) (rune, error) {
+	return r, nil
+}
+
+func self(r rune) rune {
+	return r
+}
+
+func parseRunes(t *testing.T, s string) *Grid[rune] {
+	t.Helper()
+	g, err := Parse(strings.NewReader(s), identity)
+	if err != nil {
+		t.Fatalf("Parse(%q) error: %v", s, err)
+	}
+	return g
+}
+
+func TestParse(t *testing.T) {
+	in := "123\n456\n"
+	g, err := Parse(strings.NewReader(in), func(_ geom.Point, r rune) (int, error) {
+		if r < '0' || r > '9' {
+			return 0, fmt.Errorf("not a digit: %q", r)
+		}
+		return int(r - '0'), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if g.Width() != 3 || g.Height() != 2 {
+		t.Errorf("size = %dx%d, want 3x2", g.Width(), g.Height())
+	}
+	if got := g.At(geom.Pt(2, 1)); got != 6 {
+		t.Errorf("At(2, 1) = %d, want 6", got)
+	}
+	if diff := cmp.Diff([]int{4, 5, 6}, g.Row(1)); diff != "" {
+		t.Errorf("Row(1) mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]int{2, 5}, g.Col(1)); diff != "" {
+		t.Errorf("Col(1) mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParse_Error(t *testing.T) {
+	digit := func(_ geom.Point, r rune) (int, error) {
+		if r < '0' || r > '9' {
+			return 0, fmt.Errorf("not a digit: %q", r)
+		}
+		return int(r - '0'), nil
+	}
+
+	cases := []struct {
+		in        string
+		line, col int
+	}{
+		{"123\n4x6\n", 2, 2},
+		{"123\n45\n", 2, 0},
+	}
+
+	for _, tc := range cases {
+		_, err := Parse(strings.NewReader(tc.in), digit)
+
+		var pe *aoc22.ParseError
+		if !errors.As(err, &pe) {
+			t.Errorf("Parse(%q) error = %v, want a ParseError", tc.in, err)
+			continue
+		}
+		if pe.Line != tc.line || pe.Column != tc.col {
+			t.Errorf("Parse(%q) error at %d:%d, want %d:%d", tc.in, pe.Line, pe.Column, tc.line, tc.col)
+		}
+	}
+}
+
+func TestGrid_Neighbors(t *testing.T) {
+	g := New[int](3, 3)
+
+	cases := []struct {
+		name  string
+		p     geom.Point
+		want4 []geom.Point
+		want8 []geom.Point
+	}{
+		{
+			"center",
+			geom.Pt(1, 1),
+			[]geom.Point{geom.Pt(1, 0), geom.Pt(2, 1), geom.Pt(1, 2), geom.Pt(0, 1)},
+			[]geom.Point{geom.Pt(1, 0), geom.Pt(2, 0), geom.Pt(2, 1), geom.Pt(2, 2), geom.Pt(1, 2), geom.Pt(0, 2), geom.Pt(0, 1), geom.Pt(0, 0)},
+		},
+		{
+			"corner",
+			geom.Pt(0, 0),
+			[]geom.Point{geom.Pt(1, 0), geom.Pt(0, 1)},
+			[]geom.Point{geom.Pt(1, 0), geom.Pt(1, 1), geom.Pt(0, 1)},
+		},
+	}
+
+	for _, tc := range cases {
+		if diff := cmp.Diff(tc.want4, g.Neighbors4(tc.p)); diff != "" {
+			t.Errorf("%s: Neighbors4 mismatch (-want +got):\n%s", tc.name, diff)
+		}
+		if diff := cmp.Diff(tc.want8, g.Neighbors8(tc.p)); diff != "" {
+			t.Errorf("%s: Neighbors8 mismatch (-want +got):\n%s", tc.name, diff)
+		}
+	}
+}
+
+func TestGrid_Ray(t *testing.T) {
+	g := parseRunes(t, "abcd\nefgh\nijkl\n")
+
+	cases := []struct {
+		p, d geom.Point
+		want string
+	}{
+		{geom.Pt(1, 1), geom.Pt(1, 0), "gh"},
+		{geom.Pt(1, 1), geom.Pt(-1, 0), "e"},
+		{geom.Pt(1, 1), geom.Pt(0, -1), "b"},
+		{geom.Pt(0, 0), geom.Pt(1, 1), "fk"},
+		{geom.Pt(3, 2), geom.Pt(1, 0), ""},
+	}
+
+	for _, tc := range cases {
+		if got := string(g.Ray(tc.p, tc.d)); got != tc.want {
+			t.Errorf("Ray(%v, %v) = %q, want %q", tc.p, tc.d, got, tc.want)
+		}
+	}
+}
+
+func TestGrid_Transform(t *testing.T) {
+	g := parseRunes(t, "abc\ndef\n")
+
+	cases := []struct {
+		name string
+		got  *Grid[rune]
+		want string
+	}{
+		{"Transpose", g.Transpose(), "ad\nbe\ncf\n"},
+		{"RotateCW", g.RotateCW(), "da\neb\nfc\n"},
+		{"RotateCCW", g.RotateCCW(), "cf\nbe\nad\n"},
+		{"Window", g.Window(geom.Pt(1, 0), geom.Pt(2, 1)), "bc\nef\n"},
+		{"RotateCW 4x", g.RotateCW().RotateCW().RotateCW().RotateCW(), "abc\ndef\n"},
+	}
+
+	for _, tc := range cases {
+		if got := tc.got.Render(self); got != tc.want {
+			t.Errorf("%s = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+
+	if got := g.Render(self); got != "abc\ndef\n" {
+		t.Errorf("transforms modified the grid: %q", got)
+	}
+}
+
+func TestGrid_SetAndCount(t *testing.T) {
+	g := New[bool](4, 2)
+	g.Set(geom.Pt(3, 1), true)
+	g.Set(geom.Pt(0, 0), true)
+
+	if n := g.Count(func(b bool) bool { return b }); n != 2 {
+		t.Errorf("Count() = %d, want 2", n)
+	}
+
+	c := g.Clone()
+	c.Fill(false)
+	if !g.At(geom.Pt(3, 1)) {
+		t.Error("Fill on a clone modified the original")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("At out of bounds didn't panic")
+		}
+	}()
+	g.At(geom.Pt(4, 0))
+}
@@ -0,0 +1,215 @@
+// Package interval implements sets of integers stored as inclusive ranges, for
+// puzzles about overlapping spans like cleaning assignments or sensor coverage.
+package interval
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// A Range is the set of integers from Low to High inclusive. A Range with
+// High < Low is empty.
+type Range struct {
+	Low, High int
+}
+
+// Empty reports whether r contains no integers.
+func (r Range) Empty() bool {
+	return r.High < r.Low
+}
+
+// Len returns the number of integers in r.
+func (r Range) Len() int {
+	if r.Empty() {
+		return 0
+	}
+	return r.High - r.Low + 1
+}
+
+// Contains reports whether x is in r.
+func (r Range) Contains(x int) bool {
+	return r.Low <= x && x <= r.High
+}
+
+// ContainsRange reports whether every integer in s is also in r. The empty
+// range is contained in every range.
+func (r Range) ContainsRange(s Range) bool {
+	return s.Empty() || (r.Low <= s.Low && s.High <= r.High)
+}
+
+// Overlaps reports whether r and s have any integer in common.
+func (r Range) Overlaps(s Range) bool {
+	return !r.Intersect(s).Empty()
+}
+
+// Intersect returns the integers in both r and s. The result may be empty.
+func (r Range) Intersect(s Range) Range {
+	return Range{max(r.Low, s.Low), min(r.High, s.High)}
+}
+
+func (r Range) String() string {
+	return fmt.Sprintf("[%d,%d]", r.Low, r.High)
+}
+
+// A Set is a set of integers. It's stored as a sorted list of disjoint,
+// non-adjacent ranges, so a Set with n ranges takes O(n) space no matter how
+// many integers it holds.
+//
+// The zero Set is empty and ready to use.
+type Set struct {
+	ranges []Range
+}
+
+// Of returns the union of the given ranges. It takes O(n log n) time, which
+// beats inserting the ranges one by one when there are many of them.
+func Of(rs ...Range) *Set {
+	sorted := make([]Range, 0, len(rs))
+	for _, r := range rs {
+		if !r.Empty() {
+			sorted = append(sorted, r)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Low < sorted[j].Low })
+
+	s := new(Set)
+	for _, r := range sorted {
+		if n := len(s.ranges); n > 0 && r.Low <= s.ranges[n-1].High+1 {
+			s.ranges[n-1].High = max(s.ranges[n-1].High, r.High)
+		} else {
+			s.ranges = append(s.ranges, r)
+		}
+	}
+	return s
+}
+
+// Ranges returns the ranges making up s, sorted, disjoint, and non-adjacent.
+// The caller may modify the result.
+func (s *Set) Ranges() []Range {
+	if len(s.ranges) == 0 {
+		return nil
+	}
+	return append([]Range(nil), s.ranges...)
+}
+
+// Len returns the number of integers in s.
+func (s *Set) Len() int {
+	var n int
+	for _, r := range s.ranges {
+		n += r.Len()
+	}
+	return n
+}
+
+// Contains reports whether x is in s. It takes O(log n) time.
+func (s *Set) Contains(x int) bool {
+	i := sort.Search(len(s.ranges), func(i int) bool { return s.ranges[i].High >= x })
+	return i < len(s.ranges) && s.ranges[i].Contains(x)
+}
+
+// Insert adds the integers in r to s. It takes O(n) time.
+func (s *Set) Insert(r Range) {
+	if r.Empty() {
+		return
+	}
+
+	// Ranges i through j-1 overlap or touch r, and merge with it.
+	i := sort.Search(len(s.ranges), func(i int) bool { return s.ranges[i].High+1 >= r.Low })
+	j := sort.Search(len(s.ranges), func(j int) bool { return s.ranges[j].Low-1 > r.High })
+	if i < j {
+		r.Low = min(r.Low, s.ranges[i].Low)
+		r.High = max(r.High, s.ranges[j-1].High)
+	}
+	s.splice(i, j, r)
+}
+
+// Remove removes the integers in r from s. It takes O(n) time.
+func (s *Set) Remove(r Range) {
+	if r.Empty() {
+		return
+	}
+
+	// Ranges i through j-1 overlap r. Only their ends can survive.
+	i := sort.Search(len(s.ranges), func(i int) bool { return s.ranges[i].High >= r.Low })
+	j := sort.Search(len(s.ranges), func(j int) bool { return s.ranges[j].Low > r.High })
+	if i == j {
+		return
+	}
+
+	var keep []Range
+	if left := (Range{s.ranges[i].Low, r.Low - 1}); !left.Empty() {
+		keep = append(keep, left)
+	}
+	if right := (Range{r.High + 1, s.ranges[j-1].High}); !right.Empty() {
+		keep = append(keep, right)
+	}
+	s.splice(i, j, keep...)
+}
+
+// splice replaces s.ranges[i:j] with rs.
+func (s *Set) splice(i, j int, rs ...Range) {
+	tail := append(rs, s.ranges[j:]...)
+	s.ranges = append(s.ranges[:i], tail...)
+}
+
+// Intersect returns the integers in both s and t. It takes O(n+m) time.
+func (s *Set) Intersect(t *Set) *Set {
+	result := new(Set)
+	for i, j := 0, 0; i < len(s.ranges) && j < len(t.ranges); {
+		a, b := s.ranges[i], t.ranges[j]
+		if r := a.Intersect(b); !r.Empty() {
+			result.ranges = append(result.ranges, r)
+		}
+		if a.High < b.High {
+			i++
+		} else {
+			j++
+		}
+	}
+	return result
+}
+
+// Complement returns the integers in bounds that aren't in s. It takes O(n)
+// time.
+func (s *Set) Complement(bounds Range) *Set {
+	result := new(Set)
+	next := bounds.Low
+	for _, r := range s.ranges {
+		if r.High < bounds.Low {
+			continue
+		}
+		if r.Low > bounds.High {
+			break
+		}
+		if gap := (Range{next, r.Low - 1}); !gap.Empty() {
+			result.ranges = append(result.ranges, gap)
+		}
+		next = r.High + 1
+	}
+	if last := (Range{next, bounds.High}); !last.Empty() {
+		result.ranges = append(result.ranges, last)
+	}
+	return result
+}
+
+func (s *Set) String() string {
+	parts := make([]string, len(s.ranges))
+	for i, r := range s.ranges {
+		parts[i] = r.String()
+	}
+	return "{" + strings.Join(parts, " ") + "}"
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,167 @@
+package interval
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRange(t *testing.T) {
+	r := Range{2, 5}
+
+	if got := r.Len(); got != 4 {
+		t.Errorf("%v.Len() = %d, want 4", r, got)
+	}
+	if got := (Range{3, 2}).Len(); got != 0 {
+		t.Errorf("empty Len() = %d, want 0", got)
+	}
+
+	cases := []struct {
+		s                  Range
+		contains, overlaps bool
+	}{
+		{Range{3, 4}, true, true},
+		{Range{2, 5}, true, true},
+		{Range{5, 6}, false, true},
+		{Range{0, 1}, false, false},
+		{Range{6, 2}, true, false},
+	}
+
+	for _, tc := range cases {
+		if got := r.ContainsRange(tc.s); got != tc.contains {
+			t.Errorf("%v.ContainsRange(%v) = %t, want %t", r, tc.s, got, tc.contains)
+		}
+		if got := r.Overlaps(tc.s); got != tc.overlaps {
+			t.Errorf("%v.Overlaps(%v) = %t, want %t", r, tc.s, got, tc.overlaps)
+		}
+	}
+}
+
+func TestOf(t *testing.T) {
+	cases := []struct {
+		in   []Range
+		want []Range
+	}{
+		{nil, nil},
+		{[]Range{{0, 1}, {2, 3}}, []Range{{0, 3}}},
+		{[]Range{{4, 5}, {0, 1}}, []Range{{0, 1}, {4, 5}}},
+		{[]Range{{0, 10}, {2, 3}, {-1, 0}}, []Range{{-1, 10}}},
+		{[]Range{{5, 1}, {7, 7}}, []Range{{7, 7}}},
+	}
+
+	for _, tc := range cases {
+		if diff := cmp.Diff(tc.want, Of(tc.in...).Ranges()); diff != "" {
+			t.Errorf("Of(%v) mismatch (-want +got):\n%s", tc.in, diff)
+		}
+	}
+}
+
+func TestSet_InsertRemove(t *testing.T) {
+	type op struct {
+		insert bool
+		r      Range
+	}
+
+	cases := []struct {
+		name string
+		ops  []op
+		want []Range
+	}{
+		{
+			"merge adjacent",
+			[]op{{true, Range{0, 1}}, {true, Range{4, 5}}, {true, Range{2, 3}}},
+			[]Range{{0, 5}},
+		},
+		{
+			"keep apart",
+			[]op{{true, Range{6, 7}}, {true, Range{0, 1}}, {true, Range{3, 4}}},
+			[]Range{{0, 1}, {3, 4}, {6, 7}},
+		},
+		{
+			"swallow",
+			[]op{{true, Range{1, 2}}, {true, Range{4, 5}}, {true, Range{0, 9}}},
+			[]Range{{0, 9}},
+		},
+		{
+			"split",
+			[]op{{true, Range{0, 9}}, {false, Range{3, 5}}},
+			[]Range{{0, 2}, {6, 9}},
+		},
+		{
+			"trim across ranges",
+			[]op{{true, Range{0, 3}}, {true, Range{5, 6}}, {true, Range{8, 9}}, {false, Range{2, 8}}},
+			[]Range{{0, 1}, {9, 9}},
+		},
+		{
+			"remove missing",
+			[]op{{true, Range{0, 3}}, {false, Range{5, 6}}},
+			[]Range{{0, 3}},
+		},
+		{
+			"remove everything",
+			[]op{{true, Range{0, 3}}, {false, Range{-5, 5}}},
+			nil,
+		},
+	}
+
+	for _, tc := range cases {
+		var s Set
+		for _, o := range tc.ops {
+			if o.insert {
+				s.Insert(o.r)
+			} else {
+				s.Remove(o.r)
+			}
+		}
+		if diff := cmp.Diff(tc.want, s.Ranges()); diff != "" {
+			t.Errorf("%s: mismatch (-want +got):\n%s", tc.name, diff)
+		}
+	}
+}
+
+func TestSet_Contains(t *testing.T) {
+	s := Of(Range{0, 2}, Range{5, 5}, Range{8, 10})
+
+	for x := -1; x <= 11; x++ {
+		want := (0 <= x && x <= 2) || x == 5 || (8 <= x && x <= 10)
+		if got := s.Contains(x); got != want {
+			t.Errorf("%v.Contains(%d) = %t, want %t", s, x, got, want)
+		}
+	}
+	if got := s.Len(); got != 7 {
+		t.Errorf("%v.Len() = %d, want 7", s, got)
+	}
+}
+
+func TestSet_Intersect(t *testing.T) {
+	s := Of(Range{0, 5}, Range{10, 15})
+	u := Of(Range{3, 11}, Range{14, 20})
+
+	want := []Range{{3, 5}, {10, 11}, {14, 15}}
+	if diff := cmp.Diff(want, s.Intersect(u).Ranges()); diff != "" {
+		t.Errorf("Intersect mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(want, u.Intersect(s).Ranges()); diff != "" {
+		t.Errorf("Intersect isn't symmetric (-want +got):\n%s", diff)
+	}
+}
+
+func TestSet_Complement(t *testing.T) {
+	s := Of(Range{0, 5}, Range{10, 15})
+
+	cases := []struct {
+		bounds Range
+		want   []Range
+	}{
+		{Range{0, 20}, []Range{{6, 9}, {16, 20}}},
+		{Range{-3, 12}, []Range{{-3, -1}, {6, 9}}},
+		{Range{1, 4}, nil},
+		{Range{7, 8}, []Range{{7, 8}}},
+	}
+
+	for _, tc := range cases {
+		if diff := cmp.Diff(tc.want, s.Complement(tc.bounds).Ranges()); diff != "" {
+			t.Errorf("Complement(%v) mismatch (-want +got):\n%s", tc.bounds, diff)
+		}
+	}
+}
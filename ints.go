@@ -0,0 +1,187 @@
+package aoc22
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/big"
+	"regexp"
+	"strconv"
+)
+
+// intsRegexp matches integers: runs of digits, optionally preceded by a minus
+// sign. A minus sign right after a digit is a separator instead, as in ranges
+// like "2-4", so callers must check for that.
+var intsRegexp = regexp.MustCompile(`-?\d+`)
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// readNumbers returns every integer in s, converted with parse. Errors are
+// returned as *ParseError, positioned at the offending column.
+func readNumbers[T any](s string, parse func(string) (T, error)) ([]T, error) {
+	var result []T
+	for _, loc := range intsRegexp.FindAllStringIndex(s, -1) {
+		lo, hi := loc[0], loc[1]
+		if s[lo] == '-' && lo > 0 && isDigit(s[lo-1]) {
+			lo++
+		}
+		n, err := parse(s[lo:hi])
+		if err != nil {
+			return nil, &ParseError{Column: lo + 1, Err: err}
+		}
+		result = append(result, n)
+	}
+	return result, nil
+}
+
+// ReadInts returns a slice of all integers in the given string. For example,
+// "x=-3, y=12\n2-4" becomes []int{-3, 12, 2, 4}.
+//
+// If an integer doesn't fit in an int, ReadInts returns a *ParseError with the
+// column of the integer.
+func ReadInts(s string) ([]int, error) {
+	return readNumbers(s, strconv.Atoi)
+}
+
+// ReadInts64 is like ReadInts, but returns int64s.
+func ReadInts64(s string) ([]int64, error) {
+	return readNumbers(s, func(num string) (int64, error) {
+		return strconv.ParseInt(num, 10, 64)
+	})
+}
+
+// ReadBigInts is like ReadInts, but returns big.Ints, so it never overflows.
+func ReadBigInts(s string) ([]*big.Int, error) {
+	return readNumbers(s, parseBigInt)
+}
+
+func parseBigInt(num string) (*big.Int, error) {
+	n, ok := new(big.Int).SetString(num, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid integer %q", num)
+	}
+	return n, nil
+}
+
+// An IntScanner reads the integers in a stream one at a time, skipping
+// everything else. It finds the same integers as ReadInts, without loading the
+// whole input into memory.
+//
+// Like bufio.Scanner, call Scan until it returns false, then check Err:
+//
+//	s := aoc22.NewIntScanner(r)
+//	for s.Scan() {
+//		use(s.Int())
+//	}
+//	if err := s.Err(); err != nil {
+//		return err
+//	}
+type IntScanner struct {
+	r         *bufio.Reader
+	prev      byte // The last byte read.
+	line, col int  // The position of the next byte.
+
+	tok []byte
+	n   int
+	err error
+}
+
+// NewIntScanner returns an IntScanner reading from r.
+func NewIntScanner(r io.Reader) *IntScanner {
+	return &IntScanner{r: bufio.NewReader(r), line: 1, col: 1}
+}
+
+// Scan advances to the next integer, which is then available through Int and
+// Text. It returns false at the end of the input or after an error.
+func (s *IntScanner) Scan() bool {
+	if s.err != nil {
+		return false
+	}
+	s.tok = s.tok[:0]
+
+	// Skip to the first byte of the next integer.
+	for {
+		c, ok := s.peek()
+		if !ok {
+			return false
+		}
+		if isDigit(c) || (c == '-' && !isDigit(s.prev) && s.digitAfterSign()) {
+			break
+		}
+		s.advance()
+	}
+
+	line, col := s.line, s.col
+	if c, _ := s.peek(); c == '-' {
+		s.tok = append(s.tok, s.advance())
+	}
+	for {
+		c, ok := s.peek()
+		if !ok || !isDigit(c) {
+			break
+		}
+		s.tok = append(s.tok, s.advance())
+	}
+	if s.err != nil {
+		return false
+	}
+
+	n, err := strconv.Atoi(string(s.tok))
+	if err != nil {
+		s.err = &ParseError{Line: line, Column: col, Text: string(s.tok), Err: err}
+		return false
+	}
+	s.n = n
+	return true
+}
+
+// peek returns the next byte without consuming it. It returns false at the end
+// of the input or after a read error.
+func (s *IntScanner) peek() (byte, bool) {
+	b, err := s.r.Peek(1)
+	if err != nil {
+		if err != io.EOF {
+			s.err = err
+		}
+		return 0, false
+	}
+	return b[0], true
+}
+
+// digitAfterSign reports whether the byte after the next one is a digit.
+func (s *IntScanner) digitAfterSign() bool {
+	b, _ := s.r.Peek(2)
+	return len(b) == 2 && isDigit(b[1])
+}
+
+// advance consumes and returns the next byte, which must have been peeked.
+func (s *IntScanner) advance() byte {
+	c, _ := s.r.ReadByte()
+	if c == '\n' {
+		s.line++
+		s.col = 1
+	} else {
+		s.col++
+	}
+	s.prev = c
+	return c
+}
+
+// Int returns the integer found by the last call to Scan.
+func (s *IntScanner) Int() int {
+	return s.n
+}
+
+// Text returns the integer found by the last call to Scan as written, such as
+// "-12".
+func (s *IntScanner) Text() string {
+	return string(s.tok)
+}
+
+// Err returns the first error encountered by the IntScanner. Overflowing
+// integers are reported as a *ParseError with their line and column.
+func (s *IntScanner) Err() error {
+	return s.err
+}
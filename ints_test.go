@@ -0,0 +1,96 @@
+package aoc22
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadInts(t *testing.T) {
+	cases := []struct {
+		in   string
+		want []int
+	}{
+		{"a 12\n3, b::4", []int{12, 3, 4}},
+		{"Sensor at x=-3, y=12", []int{-3, 12}},
+		{"2-4,6-8", []int{2, 4, 6, 8}},
+		{"a - b -", nil},
+		{"--5 x-0", []int{-5, 0}},
+		{"", nil},
+	}
+
+	for _, tc := range cases {
+		got, err := ReadInts(tc.in)
+		if err != nil {
+			t.Errorf("ReadInts(%q) error: %v", tc.in, err)
+			continue
+		}
+		if diff := cmp.Diff(tc.want, got); diff != "" {
+			t.Errorf("ReadInts(%q) mismatch (-want +got):\n%s", tc.in, diff)
+		}
+	}
+}
+
+func TestReadInts_Overflow(t *testing.T) {
+	in := "1, 99999999999999999999"
+
+	_, err := ReadInts(in)
+	var pe *ParseError
+	if !errors.As(err, &pe) || pe.Column != 4 {
+		t.Errorf("ReadInts(%q) error = %v, want a ParseError at column 4", in, err)
+	}
+
+	got, err := ReadBigInts(in)
+	if err != nil {
+		t.Fatalf("ReadBigInts(%q) error: %v", in, err)
+	}
+	want, _ := new(big.Int).SetString("99999999999999999999", 10)
+	if len(got) != 2 || got[1].Cmp(want) != 0 {
+		t.Errorf("ReadBigInts(%q) = %v, want [1 %v]", in, got, want)
+	}
+}
+
+func TestReadInts64(t *testing.T) {
+	got, err := ReadInts64("move -5000000000 to 7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]int64{-5000000000, 7}, got); diff != "" {
+		t.Errorf("ReadInts64 mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestIntScanner(t *testing.T) {
+	in := "Sensor at x=-3, y=12\n2-4,6-8\n-7"
+
+	var got []int
+	s := NewIntScanner(strings.NewReader(in))
+	for s.Scan() {
+		got = append(got, s.Int())
+	}
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	want, _ := ReadInts(in)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("IntScanner disagrees with ReadInts (-want +got):\n%s", diff)
+	}
+}
+
+func TestIntScanner_Overflow(t *testing.T) {
+	s := NewIntScanner(strings.NewReader("1\n2 -99999999999999999999 3"))
+	for s.Scan() {
+	}
+
+	var pe *ParseError
+	if !errors.As(s.Err(), &pe) {
+		t.Fatalf("Err() = %v, want a ParseError", s.Err())
+	}
+	if pe.Line != 2 || pe.Column != 3 || pe.Text != "-99999999999999999999" {
+		t.Errorf("Err() = %+v, want line 2, column 3", pe)
+	}
+}
@@ -0,0 +1,106 @@
+package aoc22
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// A KnownAnswer is the expected answer to one part of a puzzle for one input
+// file.
+type KnownAnswer struct {
+	Day    int    `json:"day"`
+	Part   int    `json:"part"`
+	Input  string `json:"input"`            // If relative, relative to the manifest's directory.
+	Params Params `json:"params,omitempty"` // Parameters other than the defaults.
+	Answer Answer `json:"answer"`
+	Slow   bool   `json:"slow,omitempty"` // Takes long enough to skip by default.
+}
+
+func (k KnownAnswer) String() string {
+	return fmt.Sprintf("day %d part %d on %s", k.Day, k.Part, k.Input)
+}
+
+// A Manifest is a list of known answers, usually read from answers.json at the
+// top of the repository.
+type Manifest struct {
+	Dir     string // The directory that input paths are relative to.
+	Answers []KnownAnswer
+}
+
+// ReadManifest reads a manifest file. The file holds a JSON array of
+// KnownAnswers.
+func ReadManifest(path string) (*Manifest, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	answers, err := decodeManifest(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &Manifest{Dir: filepath.Dir(path), Answers: answers}, nil
+}
+
+func decodeManifest(r io.Reader) ([]KnownAnswer, error) {
+	var answers []KnownAnswer
+
+	d := json.NewDecoder(r)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&answers); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for i, k := range answers {
+		switch {
+		case k.Day < 1 || k.Day > 25:
+			return nil, fmt.Errorf("entry %d: invalid day %d", i, k.Day)
+		case k.Part != 1 && k.Part != 2:
+			return nil, fmt.Errorf("entry %d: invalid part %d", i, k.Part)
+		case k.Input == "":
+			return nil, fmt.Errorf("entry %d: no input", i)
+		case k.Answer.IsZero():
+			return nil, fmt.Errorf("entry %d: no answer", i)
+		}
+
+		key := fmt.Sprintf("%d/%d/%s", k.Day, k.Part, filepath.Clean(k.Input))
+		if seen[key] {
+			return nil, fmt.Errorf("entry %d: duplicate %v", i, k)
+		}
+		seen[key] = true
+	}
+
+	return answers, nil
+}
+
+// Verify solves k's puzzle on its input, and reports whether the answer is
+// the expected one. It returns an error if the puzzle isn't registered, the
+// input can't be read, or the solver fails or is stopped by ctx.
+func (m *Manifest) Verify(ctx context.Context, k KnownAnswer) (Answer, bool, error) {
+	puzzle, ok := Lookup(k.Day)
+	if !ok {
+		return Answer{}, false, fmt.Errorf("no solution for day %d", k.Day)
+	}
+
+	path := k.Input
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(m.Dir, path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return Answer{}, false, err
+	}
+	defer f.Close()
+
+	got, err := puzzle.Solve(ctx, k.Part, f, k.Params)
+	if err != nil {
+		return Answer{}, false, err
+	}
+	return got, got.Equal(k.Answer), nil
+}
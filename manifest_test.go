@@ -0,0 +1,83 @@
+package aoc22
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDecodeManifest_Error(t *testing.T) {
+	cases := []string{
+		`{"day": 1}`,
+		`[{"day": 0, "part": 1, "input": "x", "answer": 1}]`,
+		`[{"day": 1, "part": 3, "input": "x", "answer": 1}]`,
+		`[{"day": 1, "part": 1, "answer": 1}]`,
+		`[{"day": 1, "part": 1, "input": "x"}]`,
+		`[{"day": 1, "part": 1, "input": "x", "answer": 1, "typo": true}]`,
+		`[{"day": 1, "part": 1, "input": "x", "answer": 1}, {"day": 1, "part": 1, "input": "./x", "answer": 2}]`,
+	}
+
+	for _, in := range cases {
+		if _, err := decodeManifest(strings.NewReader(in)); err == nil {
+			t.Errorf("decodeManifest(%s) succeeded", in)
+		}
+	}
+}
+
+func TestManifest_Verify(t *testing.T) {
+	count := func(_ context.Context, r io.Reader, params Params) (Answer, error) {
+		data, err := io.ReadAll(r)
+		return Int(len(data) * params["times"]), err
+	}
+	Register(Puzzle{
+		Day:    24,
+		Part1:  count,
+		Part2:  count,
+		Params: []Param{{Name: "times", Default: 1}},
+	})
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "in.txt"), []byte("12345"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	manifest := `[
+		{"day": 24, "part": 1, "input": "in.txt", "answer": 5},
+		{"day": 24, "part": 2, "input": "in.txt", "params": {"times": 2}, "answer": 11, "slow": true}
+	]`
+	if err := os.WriteFile(filepath.Join(dir, "answers.json"), []byte(manifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := ReadManifest(filepath.Join(dir, "answers.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Answers) != 2 || !m.Answers[1].Slow {
+		t.Fatalf("ReadManifest() = %+v", m.Answers)
+	}
+
+	cases := []struct {
+		k    KnownAnswer
+		got  Answer
+		pass bool
+	}{
+		{m.Answers[0], Int(5), true},
+		{m.Answers[1], Int(10), false},
+	}
+	for _, tc := range cases {
+		got, pass, err := m.Verify(context.Background(), tc.k)
+		if err != nil {
+			t.Errorf("Verify(%v) error: %v", tc.k, err)
+		}
+		if got != tc.got || pass != tc.pass {
+			t.Errorf("Verify(%v) = %v, %t; want %v, %t", tc.k, got, pass, tc.got, tc.pass)
+		}
+	}
+
+	if _, _, err := m.Verify(context.Background(), KnownAnswer{Day: 23, Part: 1, Input: "in.txt"}); err == nil {
+		t.Error("Verify of an unregistered day succeeded")
+	}
+}
@@ -0,0 +1,68 @@
+package aoc22
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// A ParseError describes malformed puzzle input.
+type ParseError struct {
+	Day    int    // The puzzle's day, or 0 if unknown.
+	Line   int    // The one-indexed line number, or 0 if unknown.
+	Column int    // The one-indexed byte column within the line, or 0 if unknown.
+	Text   string // The offending text, usually the whole line.
+	Err    error  // What was wrong with the text.
+}
+
+// NewParseError returns a ParseError for a line of a day's input.
+//
+// If err already is or wraps a *ParseError, such as one returned by an
+// UnmarshalText method that only knows the column, NewParseError fills in its
+// missing fields instead of wrapping it again.
+func NewParseError(day, line int, text string, err error) *ParseError {
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		return &ParseError{Day: day, Line: line, Text: text, Err: err}
+	}
+
+	result := *pe
+	if result.Day == 0 {
+		result.Day = day
+	}
+	if result.Line == 0 {
+		result.Line = line
+	}
+	if result.Text == "" {
+		result.Text = text
+	}
+	return &result
+}
+
+func (e *ParseError) Error() string {
+	var b strings.Builder
+
+	if e.Day != 0 {
+		fmt.Fprintf(&b, "day %d: ", e.Day)
+	}
+
+	switch {
+	case e.Line != 0 && e.Column != 0:
+		fmt.Fprintf(&b, "line %d, column %d: ", e.Line, e.Column)
+	case e.Line != 0:
+		fmt.Fprintf(&b, "line %d: ", e.Line)
+	case e.Column != 0:
+		fmt.Fprintf(&b, "column %d: ", e.Column)
+	}
+
+	if e.Text != "" {
+		fmt.Fprintf(&b, "%q: ", e.Text)
+	}
+
+	b.WriteString(e.Err.Error())
+	return b.String()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
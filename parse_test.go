@@ -0,0 +1,53 @@
+package aoc22
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+)
+
+func TestParseError_Error(t *testing.T) {
+	cases := []struct {
+		err  *ParseError
+		want string
+	}{
+		{
+			&ParseError{Day: 4, Line: 3, Column: 5, Text: "2-x,6-8", Err: strconv.ErrSyntax},
+			`day 4: line 3, column 5: "2-x,6-8": invalid syntax`,
+		},
+		{
+			&ParseError{Day: 1, Line: 7, Err: strconv.ErrSyntax},
+			`day 1: line 7: invalid syntax`,
+		},
+		{
+			&ParseError{Column: 2, Text: "A?", Err: strconv.ErrSyntax},
+			`column 2: "A?": invalid syntax`,
+		},
+	}
+
+	for _, tc := range cases {
+		if got := tc.err.Error(); got != tc.want {
+			t.Errorf("Error() = %q, want %q", got, tc.want)
+		}
+	}
+}
+
+func TestNewParseError(t *testing.T) {
+	inner := &ParseError{Column: 3, Text: "A?Z", Err: strconv.ErrSyntax}
+	wrapped := fmt.Errorf("round: %w", inner)
+
+	got := NewParseError(2, 10, "A?Z", wrapped)
+	want := ParseError{Day: 2, Line: 10, Column: 3, Text: "A?Z", Err: strconv.ErrSyntax}
+	if *got != want {
+		t.Errorf("NewParseError() = %#v, want %#v", *got, want)
+	}
+	if inner.Line != 0 {
+		t.Errorf("NewParseError() modified its argument")
+	}
+
+	plain := NewParseError(1, 2, "x", strconv.ErrRange)
+	if !errors.Is(plain, strconv.ErrRange) {
+		t.Errorf("NewParseError() = %v, doesn't wrap %v", plain, strconv.ErrRange)
+	}
+}
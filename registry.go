@@ -0,0 +1,138 @@
+package aoc22
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// A Solver solves one part of a day's puzzle. It reads the puzzle input from r,
+// and looks up any extra parameters the puzzle needs in params.
+//
+// Slow solvers check ctx as they go. If it's done, they return ctx.Err() along
+// with the best answer found so far, which may be the zero Answer.
+type Solver func(ctx context.Context, r io.Reader, params Params) (Answer, error)
+
+// A Param is an extra integer parameter that a puzzle needs besides its input,
+// like the row to scan in day 15.
+type Param struct {
+	Name    string
+	Usage   string
+	Default int
+}
+
+// Params maps parameter names to values.
+type Params map[string]int
+
+// Puzzle describes the solvers for one day.
+type Puzzle struct {
+	Day          int
+	Part1, Part2 Solver
+	Params       []Param
+
+	// Simulate, if set, starts a step-by-step simulation of part n on the
+	// input in r, for replaying in a terminal.
+	Simulate func(part int, r io.Reader, params Params) (Simulation, error)
+
+	// Generate, if set, writes random inputs for stress testing.
+	Generate Generator
+}
+
+// Part returns the solver for part n, which must be 1 or 2.
+func (p Puzzle) Part(n int) (Solver, error) {
+	switch n {
+	case 1:
+		return p.Part1, nil
+	case 2:
+		return p.Part2, nil
+	default:
+		return nil, fmt.Errorf("day %d: invalid part %d", p.Day, n)
+	}
+}
+
+// Solve runs part n on the input in r. Parameters missing from params take
+// their default values. If ctx is already done, the solver isn't run.
+func (p Puzzle) Solve(ctx context.Context, n int, r io.Reader, params Params) (Answer, error) {
+	solve, err := p.Part(n)
+	if err != nil {
+		return Answer{}, err
+	}
+	if err := ctx.Err(); err != nil {
+		return Answer{}, err
+	}
+
+	return solve(ctx, r, p.withDefaults(params))
+}
+
+// StartSimulation starts a step-by-step simulation of part n on the input in
+// r. Parameters missing from params take their default values. It returns an
+// error if the puzzle has no simulation.
+func (p Puzzle) StartSimulation(n int, r io.Reader, params Params) (Simulation, error) {
+	if n != 1 && n != 2 {
+		return nil, fmt.Errorf("day %d: invalid part %d", p.Day, n)
+	}
+	if p.Simulate == nil {
+		return nil, fmt.Errorf("day %d has no simulation", p.Day)
+	}
+	return p.Simulate(n, r, p.withDefaults(params))
+}
+
+// withDefaults returns params with the puzzle's defaults filled in.
+func (p Puzzle) withDefaults(params Params) Params {
+	merged := make(Params)
+	for _, param := range p.Params {
+		merged[param.Name] = param.Default
+	}
+	for name, v := range params {
+		merged[name] = v
+	}
+	return merged
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[int]Puzzle)
+)
+
+// Register makes a puzzle available to Lookup and Puzzles. It's meant to be
+// called from a day package's init function, and panics if the day is invalid,
+// a part is missing, or the day is already registered.
+func Register(p Puzzle) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if p.Day < 1 || p.Day > 25 {
+		panic(fmt.Sprintf("aoc22: invalid day %d", p.Day))
+	}
+	if p.Part1 == nil || p.Part2 == nil {
+		panic(fmt.Sprintf("aoc22: day %d is missing a part", p.Day))
+	}
+	if _, dup := registry[p.Day]; dup {
+		panic(fmt.Sprintf("aoc22: day %d registered twice", p.Day))
+	}
+	registry[p.Day] = p
+}
+
+// Lookup returns the puzzle registered for a day.
+func Lookup(day int) (Puzzle, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	p, ok := registry[day]
+	return p, ok
+}
+
+// Puzzles returns all registered puzzles, sorted by day.
+func Puzzles() []Puzzle {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	result := make([]Puzzle, 0, len(registry))
+	for _, p := range registry {
+		result = append(result, p)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Day < result[j].Day })
+	return result
+}
@@ -0,0 +1,60 @@
+package aoc22
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestRegister(t *testing.T) {
+	echo := func(_ context.Context, r io.Reader, params Params) (Answer, error) {
+		data, err := io.ReadAll(r)
+		return Text(string(data) + strings.Repeat("!", params["bang"])), err
+	}
+	Register(Puzzle{
+		Day:    25,
+		Part1:  echo,
+		Part2:  echo,
+		Params: []Param{{Name: "bang", Default: 1}},
+	})
+
+	p, ok := Lookup(25)
+	if !ok {
+		t.Fatal("Lookup(25) not ok")
+	}
+
+	cases := []struct {
+		params Params
+		want   string
+	}{
+		{nil, "hi!"},
+		{Params{"bang": 3}, "hi!!!"},
+	}
+	for _, tc := range cases {
+		got, err := p.Solve(context.Background(), 1, strings.NewReader("hi"), tc.params)
+		if err != nil {
+			t.Errorf("Solve(1, %v) error: %v", tc.params, err)
+		}
+		if got != Text(tc.want) {
+			t.Errorf("Solve(1, %v) = %v, want %q", tc.params, got, tc.want)
+		}
+	}
+
+	if _, err := p.Solve(context.Background(), 3, strings.NewReader("hi"), nil); err == nil {
+		t.Error("Solve(3) succeeded, want error")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := p.Solve(ctx, 1, strings.NewReader("hi"), nil); err != context.Canceled {
+		t.Errorf("Solve() with a canceled context error = %v, want %v", err, context.Canceled)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registering day 25 twice didn't panic")
+		}
+	}()
+	Register(Puzzle{Day: 25, Part1: echo, Part2: echo})
+}
@@ -0,0 +1,132 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+var formTemplate = template.Must(template.New("form").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>aoc22</title>
+<style>
+body { font-family: sans-serif; max-width: 40em; margin: 2em auto; }
+pre { background: #eee; padding: 0.5em; }
+.error { color: #c00; }
+</style>
+</head>
+<body>
+<h1>aoc22</h1>
+{{with .Result}}
+<h2>Day {{.Day}} part {{.Part}}</h2>
+{{with .Answer}}<pre>{{.}}</pre>{{end}}
+{{with .Error}}<p class="error">{{.}}</p>{{end}}
+<p>Took {{.Duration}} ms.</p>
+{{end}}
+<form method="post" enctype="multipart/form-data">
+<p>
+<label>Day <select name="day">{{range .Days}}<option{{if eq .Day $.Day}} selected{{end}}>{{.Day}}</option>{{end}}</select></label>
+<label>Part <select name="part"><option>1</option><option{{if eq .Part 2}} selected{{end}}>2</option></select></label>
+</p>
+<p><label>Input file <input type="file" name="input"></label></p>
+<p><label>or paste it:<br><textarea name="text" rows="10" cols="60"></textarea></label></p>
+{{range .Params}}<p><label>{{.Name}} <input type="number" name="{{.Name}}" placeholder="{{.Default}}"></label> {{.Usage}}</p>
+{{end}}<p><button>Solve</button></p>
+</form>
+</body>
+</html>
+`))
+
+type formPage struct {
+	Days      []Day
+	Params    []Param // Every day's parameters, with the day in the usage.
+	Day, Part int     // The day and part last solved.
+	Result    *Result
+}
+
+func newFormPage() *formPage {
+	page := &formPage{Days: days(), Part: 1}
+	for _, d := range page.Days {
+		for _, p := range d.Params {
+			p.Usage = fmt.Sprintf("(day %d) %s", d.Day, p.Usage)
+			page.Params = append(page.Params, p)
+		}
+	}
+	return page
+}
+
+func (s *Server) showForm(w http.ResponseWriter, r *http.Request) {
+	writeForm(w, http.StatusOK, newFormPage())
+}
+
+// submitForm solves an input uploaded with the form, and shows the result
+// above the form.
+func (s *Server) submitForm(w http.ResponseWriter, r *http.Request) {
+	page := newFormPage()
+	res := new(Result)
+	page.Result = res
+
+	r.Body = http.MaxBytesReader(w, r.Body, s.maxInputSize())
+	if err := r.ParseMultipartForm(s.maxInputSize()); err != nil {
+		status := http.StatusBadRequest
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		res.Error = err.Error()
+		writeForm(w, status, page)
+		return
+	}
+
+	// The form has every day's parameters, so only pass on the ones for
+	// the chosen day. Empty fields are left at their defaults.
+	query := make(url.Values)
+	for _, d := range page.Days {
+		if strconv.Itoa(d.Day) != r.FormValue("day") {
+			continue
+		}
+		for _, p := range d.Params {
+			if v := r.FormValue(p.Name); v != "" {
+				query.Set(p.Name, v)
+			}
+		}
+	}
+	puzzle, params, err := parseRequest(r.FormValue("day"), r.FormValue("part"), query, res)
+	page.Day, page.Part = res.Day, res.Part
+	if err != nil {
+		res.Error = err.Error()
+		writeForm(w, statusOf(err), page)
+		return
+	}
+
+	// Browsers send text areas with CRLF line endings.
+	input := []byte(strings.ReplaceAll(r.FormValue("text"), "\r\n", "\n"))
+	if f, _, err := r.FormFile("input"); err == nil {
+		defer f.Close()
+		if input, err = io.ReadAll(f); err != nil {
+			res.Error = err.Error()
+			writeForm(w, http.StatusBadRequest, page)
+			return
+		}
+	}
+	if strings.TrimSpace(string(input)) == "" {
+		res.Error = "no input"
+		writeForm(w, http.StatusBadRequest, page)
+		return
+	}
+
+	writeForm(w, s.run(r.Context(), puzzle, input, params, res), page)
+}
+
+func writeForm(w http.ResponseWriter, status int, page *formPage) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	formTemplate.Execute(w, page)
+}
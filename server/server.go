@@ -0,0 +1,257 @@
+// Package server serves the registered solvers over HTTP, so that a team can
+// share one running copy instead of everyone building the repository.
+//
+// The API has two endpoints:
+//
+//	GET  /v1/days                     lists the days and their parameters
+//	POST /v1/days/{day}/parts/{part}  solves the input in the request body
+//
+// Parameters like day 15's row go in the query string, as in
+// /v1/days/15/parts/1?y=10. Both endpoints answer with JSON. The server also
+// has a minimal HTML form at / for uploading an input from a browser.
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/clfs/aoc22"
+)
+
+const (
+	// DefaultMaxInputSize is the largest input a Server accepts unless told
+	// otherwise. Real inputs are far smaller, but generated ones needn't be.
+	DefaultMaxInputSize = 4 << 20
+
+	// DefaultTimeout is how long a Server lets a solver run unless told
+	// otherwise.
+	DefaultTimeout = 30 * time.Second
+)
+
+// A Param describes an extra parameter of a day's puzzle.
+type Param struct {
+	Name    string `json:"name"`
+	Usage   string `json:"usage"`
+	Default int    `json:"default"`
+}
+
+// A Day describes a day with registered solvers.
+type Day struct {
+	Day    int     `json:"day"`
+	Params []Param `json:"params"`
+}
+
+// A Location is where a parse error was found in the input. Fields that
+// aren't known are left out.
+type Location struct {
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
+	Text   string `json:"text,omitempty"`
+}
+
+// A Result is the response to a request to solve a puzzle.
+type Result struct {
+	Day  int `json:"day"`
+	Part int `json:"part"`
+
+	// The answer, or the best answer so far if the solver timed out. It's
+	// nil if there's no answer at all.
+	Answer *aoc22.Answer `json:"answer,omitempty"`
+
+	Duration   float64   `json:"duration_ms"`
+	Error      string    `json:"error,omitempty"`
+	ParseError *Location `json:"parse_error,omitempty"`
+}
+
+// A Server is an http.Handler serving the API and the upload form. The zero
+// value is ready to use. A Server is safe for concurrent use.
+type Server struct {
+	MaxInputSize int64         // The largest input accepted, in bytes; defaults to DefaultMaxInputSize.
+	Timeout      time.Duration // How long each solver may run; defaults to DefaultTimeout.
+
+	once sync.Once
+	mux  *http.ServeMux
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.once.Do(func() {
+		s.mux = http.NewServeMux()
+		s.mux.HandleFunc("GET /v1/days", s.listDays)
+		s.mux.HandleFunc("POST /v1/days/{day}/parts/{part}", s.solve)
+		s.mux.HandleFunc("GET /{$}", s.showForm)
+		s.mux.HandleFunc("POST /{$}", s.submitForm)
+	})
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) maxInputSize() int64 {
+	if s.MaxInputSize <= 0 {
+		return DefaultMaxInputSize
+	}
+	return s.MaxInputSize
+}
+
+func (s *Server) timeout() time.Duration {
+	if s.Timeout <= 0 {
+		return DefaultTimeout
+	}
+	return s.Timeout
+}
+
+// days returns every registered day.
+func days() []Day {
+	var result []Day
+	for _, p := range aoc22.Puzzles() {
+		d := Day{Day: p.Day, Params: []Param{}}
+		for _, param := range p.Params {
+			d.Params = append(d.Params, Param{Name: param.Name, Usage: param.Usage, Default: param.Default})
+		}
+		result = append(result, d)
+	}
+	return result
+}
+
+func (s *Server) listDays(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, struct {
+		Days []Day `json:"days"`
+	}{days()})
+}
+
+func (s *Server) solve(w http.ResponseWriter, r *http.Request) {
+	var res Result
+	puzzle, params, err := parseRequest(r.PathValue("day"), r.PathValue("part"), r.URL.Query(), &res)
+	if err != nil {
+		res.Error = err.Error()
+		writeJSON(w, statusOf(err), res)
+		return
+	}
+
+	input, err := io.ReadAll(http.MaxBytesReader(w, r.Body, s.maxInputSize()))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			res.Error = fmt.Sprintf("input is larger than %d bytes", tooLarge.Limit)
+			writeJSON(w, http.StatusRequestEntityTooLarge, res)
+			return
+		}
+		res.Error = err.Error()
+		writeJSON(w, http.StatusBadRequest, res)
+		return
+	}
+
+	status := s.run(r.Context(), puzzle, input, params, &res)
+	writeJSON(w, status, res)
+}
+
+// A requestError is a problem with the request itself, rather than with the
+// input, and carries the HTTP status to answer with.
+type requestError struct {
+	status int
+	err    error
+}
+
+func (e *requestError) Error() string { return e.err.Error() }
+
+func statusOf(err error) int {
+	var re *requestError
+	if errors.As(err, &re) {
+		return re.status
+	}
+	return http.StatusBadRequest
+}
+
+// parseRequest looks up the puzzle and parameters for a request to solve a
+// day and part, and records the day and part in res.
+func parseRequest(day, part string, query url.Values, res *Result) (aoc22.Puzzle, aoc22.Params, error) {
+	d, err := strconv.Atoi(day)
+	if err != nil {
+		return aoc22.Puzzle{}, nil, &requestError{http.StatusNotFound, fmt.Errorf("invalid day %q", day)}
+	}
+	res.Day = d
+	puzzle, ok := aoc22.Lookup(d)
+	if !ok {
+		return aoc22.Puzzle{}, nil, &requestError{http.StatusNotFound, fmt.Errorf("no solution for day %d", d)}
+	}
+
+	n, err := strconv.Atoi(part)
+	if err != nil || (n != 1 && n != 2) {
+		return aoc22.Puzzle{}, nil, &requestError{http.StatusNotFound, fmt.Errorf("invalid part %q", part)}
+	}
+	res.Part = n
+
+	params := make(aoc22.Params)
+	for name, values := range query {
+		if !slices.ContainsFunc(puzzle.Params, func(p aoc22.Param) bool { return p.Name == name }) {
+			return aoc22.Puzzle{}, nil, &requestError{http.StatusBadRequest, fmt.Errorf("day %d has no parameter %q", d, name)}
+		}
+		v, err := strconv.Atoi(values[len(values)-1])
+		if err != nil {
+			return aoc22.Puzzle{}, nil, &requestError{http.StatusBadRequest, fmt.Errorf("parameter %s: invalid integer %q", name, values[len(values)-1])}
+		}
+		params[name] = v
+	}
+
+	return puzzle, params, nil
+}
+
+// run solves the puzzle on input, filling in res, and returns the HTTP status
+// to answer with.
+func (s *Server) run(ctx context.Context, puzzle aoc22.Puzzle, input []byte, params aoc22.Params, res *Result) int {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout())
+	defer cancel()
+
+	start := time.Now()
+	answer, err := solve(ctx, puzzle, res.Part, input, params)
+	res.Duration = float64(time.Since(start).Microseconds()) / 1000
+	if !answer.IsZero() {
+		res.Answer = &answer
+	}
+	if err == nil {
+		return http.StatusOK
+	}
+
+	res.Error = err.Error()
+	var pe *aoc22.ParseError
+	switch {
+	case errors.Is(err, errPanic):
+		return http.StatusInternalServerError
+	case errors.As(err, &pe):
+		res.ParseError = &Location{Line: pe.Line, Column: pe.Column, Text: pe.Text}
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusUnprocessableEntity
+	}
+}
+
+// errPanic marks a solver that panicked, which is a bug in the solver rather
+// than a problem with the input.
+var errPanic = errors.New("solver panicked")
+
+// solve solves the puzzle on input, turning a panic into an error wrapping
+// errPanic, so that one bad solver can't take down the server.
+func solve(ctx context.Context, puzzle aoc22.Puzzle, part int, input []byte, params aoc22.Params) (answer aoc22.Answer, err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			answer, err = aoc22.Answer{}, fmt.Errorf("%w: %v", errPanic, v)
+		}
+	}()
+	return puzzle.Solve(ctx, part, bytes.NewReader(input), params)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
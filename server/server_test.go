@@ -0,0 +1,177 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/clfs/aoc22"
+)
+
+// Day 25 is a stand-in puzzle. Part 1 sums the numbers on each line, times
+// the scale parameter, and panics on a line reading "panic". Part 2 runs until
+// it's stopped.
+func init() {
+	aoc22.Register(aoc22.Puzzle{
+		Day: 25,
+		Part1: func(ctx context.Context, r io.Reader, params aoc22.Params) (aoc22.Answer, error) {
+			var sum int
+			s := bufio.NewScanner(r)
+			for line := 1; s.Scan(); line++ {
+				if s.Text() == "panic" {
+					panic("told to")
+				}
+				n, err := strconv.Atoi(s.Text())
+				if err != nil {
+					return aoc22.Answer{}, aoc22.NewParseError(25, line, s.Text(), err)
+				}
+				sum += n
+			}
+			return aoc22.Int(sum * params["scale"]), s.Err()
+		},
+		Part2: func(ctx context.Context, r io.Reader, params aoc22.Params) (aoc22.Answer, error) {
+			<-ctx.Done()
+			return aoc22.Int(7), ctx.Err()
+		},
+		Params: []aoc22.Param{{Name: "scale", Usage: "what to multiply the sum by", Default: 1}},
+	})
+}
+
+func TestListDays(t *testing.T) {
+	srv := httptest.NewServer(new(Server))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/v1/days")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var got struct{ Days []Day }
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Days) != 1 || got.Days[0].Day != 25 {
+		t.Fatalf("got days %+v, want just day 25", got.Days)
+	}
+	want := Param{Name: "scale", Usage: "what to multiply the sum by", Default: 1}
+	if params := got.Days[0].Params; len(params) != 1 || params[0] != want {
+		t.Errorf("got params %+v, want [%+v]", params, want)
+	}
+}
+
+func TestSolve(t *testing.T) {
+	cases := []struct {
+		name       string
+		path, body string
+		status     int
+		answer     string // Empty if there's no answer.
+		parseError *Location
+	}{
+		{"answer", "/v1/days/25/parts/1", "1\n2\n3\n", http.StatusOK, "6", nil},
+		{"param", "/v1/days/25/parts/1?scale=10", "1\n2\n3\n", http.StatusOK, "60", nil},
+		{"unknown param", "/v1/days/25/parts/1?size=10", "1\n", http.StatusBadRequest, "", nil},
+		{"bad param", "/v1/days/25/parts/1?scale=x", "1\n", http.StatusBadRequest, "", nil},
+		{"unknown day", "/v1/days/24/parts/1", "1\n", http.StatusNotFound, "", nil},
+		{"bad part", "/v1/days/25/parts/3", "1\n", http.StatusNotFound, "", nil},
+		{"parse error", "/v1/days/25/parts/1", "1\nx\n", http.StatusUnprocessableEntity, "", &Location{Line: 2, Text: "x"}},
+		{"too large", "/v1/days/25/parts/1", strings.Repeat("1\n", 100), http.StatusRequestEntityTooLarge, "", nil},
+		{"panic", "/v1/days/25/parts/1", "1\npanic\n", http.StatusInternalServerError, "", nil},
+		{"timeout", "/v1/days/25/parts/2", "1\n", http.StatusGatewayTimeout, "7", nil},
+	}
+
+	srv := httptest.NewServer(&Server{MaxInputSize: 100, Timeout: 10 * time.Millisecond})
+	defer srv.Close()
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := http.Post(srv.URL+tc.path, "text/plain", strings.NewReader(tc.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			var got Result
+			if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tc.status {
+				t.Errorf("status = %d, want %d (error %q)", resp.StatusCode, tc.status, got.Error)
+			}
+			if (got.Error == "") != (tc.status == http.StatusOK) {
+				t.Errorf("error = %q with status %d", got.Error, resp.StatusCode)
+			}
+
+			var answer string
+			if got.Answer != nil {
+				answer = got.Answer.String()
+			}
+			if answer != tc.answer {
+				t.Errorf("answer = %q, want %q", answer, tc.answer)
+			}
+
+			switch {
+			case tc.parseError == nil && got.ParseError != nil:
+				t.Errorf("parse error = %+v, want none", *got.ParseError)
+			case tc.parseError != nil && (got.ParseError == nil || *got.ParseError != *tc.parseError):
+				t.Errorf("parse error = %+v, want %+v", got.ParseError, *tc.parseError)
+			}
+		})
+	}
+}
+
+func TestSolve_MethodNotAllowed(t *testing.T) {
+	srv := httptest.NewServer(new(Server))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/v1/days/25/parts/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestForm(t *testing.T) {
+	srv := httptest.NewServer(new(Server))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !bytes.Contains(page, []byte(`<input type="number" name="scale"`)) {
+		t.Fatalf("GET / = %d with a page missing the scale field:\n%s", resp.StatusCode, page)
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	mw.WriteField("day", "25")
+	mw.WriteField("part", "1")
+	mw.WriteField("text", "1\r\n2\r\n")
+	mw.WriteField("scale", "")
+	mw.Close()
+
+	resp, err = http.Post(srv.URL, mw.FormDataContentType(), &body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !bytes.Contains(page, []byte("<pre>3</pre>")) {
+		t.Errorf("POST / = %d with a page missing the answer:\n%s", resp.StatusCode, page)
+	}
+}
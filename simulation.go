@@ -0,0 +1,105 @@
+package aoc22
+
+// A Simulation advances a puzzle's state one step at a time, like a grain of
+// sand in day 14 or a cycle of the day 10 CPU.
+type Simulation interface {
+	// Step advances the simulation by one step. It returns false, and
+	// leaves the state alone, once the simulation is over.
+	Step() bool
+
+	// Render draws the current state as text.
+	Render() string
+}
+
+// DefaultSnapshots is the number of snapshots a Player keeps unless told
+// otherwise.
+const DefaultSnapshots = 1000
+
+// A Player replays a Simulation. It keeps a snapshot of the rendered state
+// after each step, so it can step back as well as forward.
+type Player struct {
+	sim   Simulation
+	max   int
+	shots []string // Snapshots of steps first, first+1, and so on.
+	first int      // The step of shots[0].
+	pos   int      // The step being shown.
+	done  bool     // Whether sim is over.
+}
+
+// NewPlayer returns a Player showing the initial state of sim. It keeps at
+// most max snapshots, dropping the oldest ones first; if max isn't positive,
+// it keeps DefaultSnapshots.
+func NewPlayer(sim Simulation, max int) *Player {
+	if max <= 0 {
+		max = DefaultSnapshots
+	}
+	return &Player{sim: sim, max: max, shots: []string{sim.Render()}}
+}
+
+// Step returns the step being shown. The initial state is step 0.
+func (p *Player) Step() int {
+	return p.pos
+}
+
+// Frame returns the rendered state at the current step.
+func (p *Player) Frame() string {
+	return p.shots[p.pos-p.first]
+}
+
+// Last returns the last step simulated so far.
+func (p *Player) Last() int {
+	return p.first + len(p.shots) - 1
+}
+
+// Done reports whether the simulation is over and the last step is shown.
+func (p *Player) Done() bool {
+	return p.done && p.pos == p.Last()
+}
+
+// Forward moves to the next step, simulating it if it hasn't been yet. It
+// returns false if the simulation is over.
+func (p *Player) Forward() bool {
+	if p.pos < p.Last() {
+		p.pos++
+		return true
+	}
+	if p.done || !p.sim.Step() {
+		p.done = true
+		return false
+	}
+
+	p.shots = append(p.shots, p.sim.Render())
+	if len(p.shots) > p.max {
+		// Reslicing is amortized O(1): append copies the kept snapshots
+		// to a new array once this one runs out of room.
+		p.shots = p.shots[1:]
+		p.first++
+	}
+	p.pos++
+	return true
+}
+
+// Back moves to the previous step. It returns false if there's no snapshot of
+// the previous step.
+func (p *Player) Back() bool {
+	if p.pos == p.first {
+		return false
+	}
+	p.pos--
+	return true
+}
+
+// Seek moves to step n, or as close to it as possible: the oldest snapshot
+// kept, or the end of the simulation.
+func (p *Player) Seek(n int) {
+	if n < p.first {
+		n = p.first
+	}
+	if n <= p.Last() {
+		p.pos = n
+		return
+	}
+	p.pos = p.Last()
+	for p.pos < n && p.Forward() {
+	}
+}
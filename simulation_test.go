@@ -0,0 +1,78 @@
+package aoc22
+
+import (
+	"strconv"
+	"testing"
+)
+
+// counter is a Simulation that counts up to n.
+type counter struct{ i, n int }
+
+func (c *counter) Step() bool {
+	if c.i == c.n {
+		return false
+	}
+	c.i++
+	return true
+}
+
+func (c *counter) Render() string { return strconv.Itoa(c.i) }
+
+func TestPlayer(t *testing.T) {
+	p := NewPlayer(&counter{n: 5}, 0)
+	if got := p.Frame(); got != "0" {
+		t.Errorf("initial Frame() = %q, want %q", got, "0")
+	}
+	if p.Back() {
+		t.Error("Back() at step 0 = true, want false")
+	}
+
+	for i := 1; i <= 5; i++ {
+		if !p.Forward() {
+			t.Fatalf("Forward() to step %d = false, want true", i)
+		}
+	}
+	if p.Done() {
+		t.Error("Done() before the end was found = true, want false")
+	}
+	if p.Forward() {
+		t.Error("Forward() past the end = true, want false")
+	}
+	if !p.Done() {
+		t.Error("Done() at the end = false, want true")
+	}
+
+	p.Seek(2)
+	if p.Step() != 2 || p.Frame() != "2" || p.Done() {
+		t.Errorf("after Seek(2): step %d, frame %q, done %t", p.Step(), p.Frame(), p.Done())
+	}
+	if !p.Back() || p.Frame() != "1" {
+		t.Errorf("after Back(): frame %q, want %q", p.Frame(), "1")
+	}
+	p.Seek(100)
+	if p.Step() != 5 || p.Last() != 5 {
+		t.Errorf("after Seek(100): step %d, last %d; want 5, 5", p.Step(), p.Last())
+	}
+}
+
+func TestPlayer_History(t *testing.T) {
+	p := NewPlayer(&counter{n: 100}, 3)
+	p.Seek(10)
+	if p.Step() != 10 {
+		t.Fatalf("after Seek(10): step %d, want 10", p.Step())
+	}
+
+	// Only steps 8, 9 and 10 are kept.
+	p.Seek(0)
+	if p.Step() != 8 || p.Frame() != "8" {
+		t.Errorf("after Seek(0): step %d, frame %q; want 8, %q", p.Step(), p.Frame(), "8")
+	}
+	if p.Back() {
+		t.Error("Back() before the oldest snapshot = true, want false")
+	}
+	for i := 9; i <= 12; i++ {
+		if !p.Forward() || p.Frame() != strconv.Itoa(i) {
+			t.Errorf("Forward() to step %d: frame %q", i, p.Frame())
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package submit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// An Attempt is one submitted answer and what came of it.
+type Attempt struct {
+	Year    int       `json:"year"`
+	Day     int       `json:"day"`
+	Part    int       `json:"part"`
+	Answer  string    `json:"answer"`
+	Outcome Outcome   `json:"outcome"`
+	Time    time.Time `json:"time"`
+}
+
+// A History is a record of submitted answers, stored as a JSON file.
+type History struct {
+	Attempts []Attempt `json:"attempts"`
+}
+
+// Errors returned by History.Check.
+var (
+	ErrKnownWrong = errors.New("answer is already known to be wrong")
+	ErrSolved     = errors.New("part is already solved")
+)
+
+// LoadHistory reads a history file. A missing file is an empty history.
+func LoadHistory(path string) (*History, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &History{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var h History
+	if err := json.Unmarshal(data, &h); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &h, nil
+}
+
+// Save writes h to a history file, creating its directory if needed.
+func (h *History) Save(path string) error {
+	data, err := json.MarshalIndent(h, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// DefaultHistoryFile returns the default history file, under the user's
+// configuration directory next to the session file.
+func DefaultHistoryFile() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "aoc22", "history.json"), nil
+}
+
+// Record adds an attempt to the history.
+func (h *History) Record(a Attempt) {
+	h.Attempts = append(h.Attempts, a)
+}
+
+// attempts returns the past attempts for a year, day, and part.
+func (h *History) attempts(year, day, part int) []Attempt {
+	var result []Attempt
+	for _, a := range h.Attempts {
+		if a.Year == year && a.Day == day && a.Part == part {
+			result = append(result, a)
+		}
+	}
+	return result
+}
+
+// Check returns an error if submitting answer would be pointless: because it
+// was already rejected, or because the part is already solved. Answers are
+// compared after trimming surrounding whitespace.
+func (h *History) Check(year, day, part int, answer string) error {
+	answer = strings.TrimSpace(answer)
+	for _, a := range h.attempts(year, day, part) {
+		switch {
+		case a.Outcome == Correct:
+			return fmt.Errorf("%w: the answer was %s", ErrSolved, a.Answer)
+		case a.Outcome.IsWrong() && strings.TrimSpace(a.Answer) == answer:
+			return fmt.Errorf("%w: %s was %s on %s", ErrKnownWrong, answer, a.Outcome, a.Time.Format(time.RFC1123))
+		}
+	}
+	return nil
+}
+
+// A Bracket is what past guesses say about a numeric answer: it's more than
+// Low and less than High. Either end may be nil if no guess was on that side.
+type Bracket struct {
+	Low, High *big.Int
+}
+
+// Bracket returns the tightest bracket known for a year, day, and part.
+func (h *History) Bracket(year, day, part int) Bracket {
+	var b Bracket
+	for _, a := range h.attempts(year, day, part) {
+		n, ok := new(big.Int).SetString(strings.TrimSpace(a.Answer), 10)
+		if !ok {
+			continue
+		}
+		switch a.Outcome {
+		case TooLow:
+			if b.Low == nil || n.Cmp(b.Low) > 0 {
+				b.Low = n
+			}
+		case TooHigh:
+			if b.High == nil || n.Cmp(b.High) < 0 {
+				b.High = n
+			}
+		}
+	}
+	return b
+}
+
+// Contains reports whether answer could be right, given the bracket. Answers
+// that aren't integers are always possible.
+func (b Bracket) Contains(answer string) bool {
+	n, ok := new(big.Int).SetString(strings.TrimSpace(answer), 10)
+	if !ok {
+		return true
+	}
+	return (b.Low == nil || n.Cmp(b.Low) > 0) && (b.High == nil || n.Cmp(b.High) < 0)
+}
+
+func (b Bracket) String() string {
+	switch {
+	case b.Low != nil && b.High != nil:
+		return fmt.Sprintf("between %v and %v", b.Low, b.High)
+	case b.Low != nil:
+		return fmt.Sprintf("more than %v", b.Low)
+	case b.High != nil:
+		return fmt.Sprintf("less than %v", b.High)
+	default:
+		return "anything"
+	}
+}
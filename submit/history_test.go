@@ -0,0 +1,100 @@
+package submit
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestHistory_Check(t *testing.T) {
+	var h History
+	h.Record(Attempt{Year: 2022, Day: 3, Part: 1, Answer: "100", Outcome: TooHigh})
+	h.Record(Attempt{Year: 2022, Day: 3, Part: 1, Answer: "50", Outcome: RateLimited})
+	h.Record(Attempt{Year: 2022, Day: 4, Part: 1, Answer: "7", Outcome: Correct})
+
+	cases := []struct {
+		day    int
+		answer string
+		want   error
+	}{
+		{3, "100", ErrKnownWrong},
+		{3, " 100\n", ErrKnownWrong},
+		{3, "50", nil},
+		{3, "99", nil},
+		{4, "8", ErrSolved},
+		{5, "100", nil},
+	}
+
+	for _, tc := range cases {
+		if err := h.Check(2022, tc.day, 1, tc.answer); !errors.Is(err, tc.want) {
+			t.Errorf("Check(day %d, %q) = %v, want %v", tc.day, tc.answer, err, tc.want)
+		}
+	}
+}
+
+func TestHistory_Bracket(t *testing.T) {
+	var h History
+	for _, a := range []Attempt{
+		{Answer: "100", Outcome: TooHigh},
+		{Answer: "80", Outcome: TooHigh},
+		{Answer: "10", Outcome: TooLow},
+		{Answer: "20", Outcome: TooLow},
+		{Answer: "50", Outcome: Wrong},
+	} {
+		a.Year, a.Day, a.Part = 2022, 1, 2
+		h.Record(a)
+	}
+
+	b := h.Bracket(2022, 1, 2)
+	if got := b.String(); got != "between 20 and 80" {
+		t.Errorf("Bracket() = %s, want between 20 and 80", got)
+	}
+
+	cases := []struct {
+		answer string
+		want   bool
+	}{
+		{"21", true},
+		{"79", true},
+		{"20", false},
+		{"85", false},
+		{"-3", false},
+		{"ZGCJZJFL", true},
+	}
+	for _, tc := range cases {
+		if got := b.Contains(tc.answer); got != tc.want {
+			t.Errorf("%v.Contains(%q) = %t, want %t", b, tc.answer, got, tc.want)
+		}
+	}
+
+	if got := h.Bracket(2022, 1, 1); got.Low != nil || got.High != nil || !got.Contains("5") {
+		t.Errorf("Bracket() for an unguessed part = %v, want anything", got)
+	}
+}
+
+func TestHistory_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "history.json")
+
+	h, err := LoadHistory(path)
+	if err != nil {
+		t.Fatalf("LoadHistory of a missing file: %v", err)
+	}
+	h.Record(Attempt{
+		Year: 2022, Day: 9, Part: 2, Answer: "2427", Outcome: TooLow,
+		Time: time.Date(2022, 12, 9, 5, 30, 0, 0, time.UTC),
+	})
+	if err := h.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LoadHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(h, got); diff != "" {
+		t.Errorf("history mismatch after saving (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,187 @@
+// Package submit posts puzzle answers to Advent of Code and keeps a history of
+// them, so that an answer the website has already rejected is never sent
+// again.
+package submit
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/clfs/aoc22/fetch"
+)
+
+// An Outcome is what the website made of a submitted answer.
+type Outcome int
+
+const (
+	Unknown       Outcome = iota // The response wasn't understood.
+	Correct                      // The answer is right.
+	Wrong                        // The answer is wrong.
+	TooHigh                      // The answer is wrong, and too high.
+	TooLow                       // The answer is wrong, and too low.
+	RateLimited                  // Nothing was checked; try again after a wait.
+	AlreadySolved                // Nothing was checked; the part is already solved.
+)
+
+var outcomeNames = []string{
+	Unknown:       "unknown",
+	Correct:       "correct",
+	Wrong:         "wrong",
+	TooHigh:       "too high",
+	TooLow:        "too low",
+	RateLimited:   "rate limited",
+	AlreadySolved: "already solved",
+}
+
+func (o Outcome) String() string {
+	if o < 0 || int(o) >= len(outcomeNames) {
+		return fmt.Sprintf("Outcome(%d)", int(o))
+	}
+	return outcomeNames[o]
+}
+
+// IsWrong reports whether the answer was checked and found wrong.
+func (o Outcome) IsWrong() bool {
+	return o == Wrong || o == TooHigh || o == TooLow
+}
+
+// MarshalText encodes o as its name, which keeps the history readable.
+func (o Outcome) MarshalText() ([]byte, error) {
+	return []byte(o.String()), nil
+}
+
+// UnmarshalText decodes an outcome's name.
+func (o *Outcome) UnmarshalText(text []byte) error {
+	for i, name := range outcomeNames {
+		if name == string(text) {
+			*o = Outcome(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown outcome %q", text)
+}
+
+// A Result is the website's response to a submission.
+type Result struct {
+	Outcome Outcome
+	Wait    time.Duration // How long to wait before submitting again, if known.
+	Message string        // The response's main text.
+}
+
+var (
+	articleRegexp = regexp.MustCompile(`(?s)<article[^>]*>(.*?)</article>`)
+	tagRegexp     = regexp.MustCompile(`<[^>]*>`)
+	leftRegexp    = regexp.MustCompile(`You have (?:(\d+)m )?(\d+)s left to wait`)
+	minutesRegexp = regexp.MustCompile(`wait (one|\d+) minutes? before trying again`)
+)
+
+// ParseResponse turns the HTML page returned for a submission into a Result.
+func ParseResponse(page string) Result {
+	msg := page
+	if m := articleRegexp.FindStringSubmatch(page); m != nil {
+		msg = m[1]
+	}
+	msg = html.UnescapeString(tagRegexp.ReplaceAllString(msg, ""))
+	msg = strings.Join(strings.Fields(msg), " ")
+
+	r := Result{Message: msg}
+	switch {
+	case strings.Contains(msg, "That's the right answer"):
+		r.Outcome = Correct
+	case strings.Contains(msg, "answer is too high"):
+		r.Outcome = TooHigh
+	case strings.Contains(msg, "answer is too low"):
+		r.Outcome = TooLow
+	case strings.Contains(msg, "That's not the right answer"):
+		r.Outcome = Wrong
+	case strings.Contains(msg, "You gave an answer too recently"):
+		r.Outcome = RateLimited
+	case strings.Contains(msg, "Did you already complete it"):
+		r.Outcome = AlreadySolved
+	}
+
+	if m := leftRegexp.FindStringSubmatch(msg); m != nil {
+		mins, _ := strconv.Atoi(m[1])
+		secs, _ := strconv.Atoi(m[2])
+		r.Wait = time.Duration(mins)*time.Minute + time.Duration(secs)*time.Second
+	} else if m := minutesRegexp.FindStringSubmatch(msg); m != nil {
+		mins := 1
+		if m[1] != "one" {
+			mins, _ = strconv.Atoi(m[1])
+		}
+		r.Wait = time.Duration(mins) * time.Minute
+	}
+
+	return r
+}
+
+// A Client submits answers. A Client is safe for concurrent use.
+type Client struct {
+	BaseURL    string       // Defaults to fetch.DefaultBaseURL.
+	Session    string       // The session token. Required.
+	UserAgent  string       // Defaults to fetch.DefaultUserAgent.
+	HTTPClient *http.Client // Defaults to http.DefaultClient.
+}
+
+// Submit posts an answer for a year, day, and part.
+//
+// Submit doesn't look at any History; callers should check one first, and
+// record the result afterwards.
+func (c *Client) Submit(ctx context.Context, year, day, part int, answer string) (Result, error) {
+	if c.Session == "" {
+		return Result{}, fetch.ErrNoSession
+	}
+
+	base := c.BaseURL
+	if base == "" {
+		base = fetch.DefaultBaseURL
+	}
+	u := fmt.Sprintf("%s/%d/day/%d/answer", strings.TrimSuffix(base, "/"), year, day)
+	form := url.Values{
+		"level":  {strconv.Itoa(part)},
+		"answer": {answer},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Result{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(&http.Cookie{Name: "session", Value: c.Session})
+	ua := c.UserAgent
+	if ua == "" {
+		ua = fetch.DefaultUserAgent
+	}
+	req.Header.Set("User-Agent", ua)
+
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return Result{}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Result{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		if len(body) > 512 {
+			body = body[:512]
+		}
+		return Result{}, &fetch.StatusError{StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(body))}
+	}
+
+	return ParseResponse(string(body)), nil
+}
@@ -0,0 +1,79 @@
+package submit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// Trimmed-down versions of the website's responses.
+const (
+	correctPage = `<main><article><p>That's the right answer!  You are one gold star closer to saving your vacation. <a href="/2022/day/3#part2">[Continue to Part Two]</a></p></article></main>`
+	tooHighPage = `<main><article><p>That's not the right answer; your answer is too high.  If you're stuck, make sure you're using the full input data. Please wait one minute before trying again. [<a href="/2022/day/3">Return to Day 3</a>]</p></article></main>`
+	tooLowPage  = `<main><article><p>That's not the right answer; your answer is too low.  Please wait 5 minutes before trying again.</p></article></main>`
+	wrongPage   = `<main><article><p>That's not the right answer.  If you're stuck, make sure you're using the full input data.</p></article></main>`
+	limitedPage = `<main><article><p>You gave an answer too recently; you have to wait after submitting an answer before trying again.  You have 4m 25s left to wait. [<a href="/2022/day/3">Return to Day 3</a>]</p></article></main>`
+	solvedPage  = `<main><article><p>You don't seem to be solving the right level.  Did you already complete it? [<a href="/2022/day/3">Return to Day 3</a>]</p></article></main>`
+)
+
+func TestParseResponse(t *testing.T) {
+	cases := []struct {
+		page string
+		want Outcome
+		wait time.Duration
+	}{
+		{correctPage, Correct, 0},
+		{tooHighPage, TooHigh, time.Minute},
+		{tooLowPage, TooLow, 5 * time.Minute},
+		{wrongPage, Wrong, 0},
+		{limitedPage, RateLimited, 4*time.Minute + 25*time.Second},
+		{solvedPage, AlreadySolved, 0},
+		{"<html>Internal error</html>", Unknown, 0},
+	}
+
+	for _, tc := range cases {
+		got := ParseResponse(tc.page)
+		if got.Outcome != tc.want || got.Wait != tc.wait {
+			t.Errorf("ParseResponse(%.40q...) = %v, %v; want %v, %v", tc.page, got.Outcome, got.Wait, tc.want, tc.wait)
+		}
+	}
+
+	if got, want := ParseResponse(wrongPage).Message, "That's not the right answer. If you're stuck, make sure you're using the full input data."; got != want {
+		t.Errorf("Message = %q, want %q", got, want)
+	}
+}
+
+func TestClient_Submit(t *testing.T) {
+	var got struct {
+		path, level, answer, session string
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		got.path = r.URL.Path
+		got.level = r.PostFormValue("level")
+		got.answer = r.PostFormValue("answer")
+		if c, err := r.Cookie("session"); err == nil {
+			got.session = c.Value
+		}
+		fmt.Fprint(w, tooLowPage)
+	}))
+	defer srv.Close()
+
+	c := &Client{BaseURL: srv.URL, Session: "secret"}
+	res, err := c.Submit(context.Background(), 2022, 3, 2, "1234")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Outcome != TooLow {
+		t.Errorf("Outcome = %v, want %v", res.Outcome, TooLow)
+	}
+	if got.path != "/2022/day/3/answer" || got.level != "2" || got.answer != "1234" || got.session != "secret" {
+		t.Errorf("server got %+v", got)
+	}
+}
@@ -0,0 +1,113 @@
+package aoc22
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"slices"
+	"sync"
+)
+
+// A Tracer receives events from a running simulation, like each cycle of the
+// day 10 CPU or each grain of sand in day 14. An event has a short dotted name,
+// like "sand.rest", and structured attributes.
+//
+// Simulations hold a Tracer that is nil by default, and skip building events
+// entirely when it is, so tracing costs nothing unless it's turned on.
+type Tracer interface {
+	Trace(event string, attrs ...slog.Attr)
+}
+
+type tracerKey struct{}
+
+// WithTracer returns a copy of ctx that carries t. Solvers pass it on to their
+// simulations.
+func WithTracer(ctx context.Context, t Tracer) context.Context {
+	return context.WithValue(ctx, tracerKey{}, t)
+}
+
+// TracerFrom returns the Tracer carried by ctx, or nil if there isn't one.
+func TracerFrom(ctx context.Context) Tracer {
+	t, _ := ctx.Value(tracerKey{}).(Tracer)
+	return t
+}
+
+// A SlogTracer logs events to a slog.Logger, with the event name as the
+// message.
+type SlogTracer struct {
+	Logger *slog.Logger
+	Level  slog.Level
+}
+
+func (t SlogTracer) Trace(event string, attrs ...slog.Attr) {
+	t.Logger.LogAttrs(context.Background(), t.Level, event, attrs...)
+}
+
+// NewJSONTracer returns a Tracer that writes each event to w as one line of
+// JSON, like
+//
+//	{"event":"sand.rest","x":500,"y":8}
+//
+// It's safe for concurrent use if w is.
+func NewJSONTracer(w io.Writer) Tracer {
+	h := slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) > 0 {
+				return a
+			}
+			switch a.Key {
+			case slog.TimeKey, slog.LevelKey:
+				return slog.Attr{}
+			case slog.MessageKey:
+				return slog.Attr{Key: "event", Value: a.Value}
+			}
+			return a
+		},
+	})
+	return SlogTracer{Logger: slog.New(h), Level: slog.LevelDebug}
+}
+
+// An Event is a traced event kept by a Recorder.
+type Event struct {
+	Name  string
+	Attrs []slog.Attr
+}
+
+// Value returns the value of the event's attribute with the given key.
+func (e Event) Value(key string) (slog.Value, bool) {
+	for _, a := range e.Attrs {
+		if a.Key == key {
+			return a.Value, true
+		}
+	}
+	return slog.Value{}, false
+}
+
+// A Recorder is a Tracer that keeps every event in memory, for tests to check.
+// It's safe for concurrent use.
+type Recorder struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (r *Recorder) Trace(event string, attrs ...slog.Attr) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, Event{Name: event, Attrs: append([]slog.Attr(nil), attrs...)})
+}
+
+// Events returns the recorded events in order. If names are given, only events
+// with those names are returned.
+func (r *Recorder) Events(names ...string) []Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var result []Event
+	for _, e := range r.events {
+		if len(names) == 0 || slices.Contains(names, e.Name) {
+			result = append(result, e)
+		}
+	}
+	return result
+}
@@ -0,0 +1,60 @@
+package aoc22
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+)
+
+func TestTracerFrom(t *testing.T) {
+	if tr := TracerFrom(context.Background()); tr != nil {
+		t.Errorf("TracerFrom(Background) = %v, want nil", tr)
+	}
+
+	r := new(Recorder)
+	if tr := TracerFrom(WithTracer(context.Background(), r)); tr != r {
+		t.Errorf("TracerFrom(WithTracer(r)) = %v, want r", tr)
+	}
+}
+
+func TestNewJSONTracer(t *testing.T) {
+	var buf bytes.Buffer
+	tr := NewJSONTracer(&buf)
+	tr.Trace("sand.rest", slog.Int("x", 500), slog.Int("y", 8))
+	tr.Trace("floor.add", slog.Group("at", slog.Int("y", 11)))
+
+	want := `{"event":"sand.rest","x":500,"y":8}
+{"event":"floor.add","at":{"y":11}}
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	r := new(Recorder)
+	attrs := []slog.Attr{slog.Int("cycle", 1)}
+	r.Trace("cpu.start", attrs...)
+	r.Trace("crt.draw", slog.Bool("lit", true))
+	r.Trace("cpu.start", slog.Int("cycle", 2))
+	attrs[0] = slog.Int("cycle", 99) // The recorder keeps its own copy.
+
+	if got := len(r.Events()); got != 3 {
+		t.Errorf("len(Events()) = %d, want 3", got)
+	}
+
+	starts := r.Events("cpu.start")
+	if len(starts) != 2 {
+		t.Fatalf("len(Events(%q)) = %d, want 2", "cpu.start", len(starts))
+	}
+	for i, e := range starts {
+		v, ok := e.Value("cycle")
+		if !ok || v.Int64() != int64(i+1) {
+			t.Errorf("event %d cycle = %v, %t; want %d", i, v, ok, i+1)
+		}
+	}
+	if _, ok := starts[0].Value("lit"); ok {
+		t.Error(`Value("lit") found a missing attribute`)
+	}
+}